@@ -3,9 +3,6 @@
 package collector
 
 import (
-	"errors"
-
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -14,6 +11,8 @@ func init() {
 	registerCollector("ad", NewADCollector)
 }
 
+var adLog = log.With("collector", "ad")
+
 // A ADCollector is a Prometheus collector for WMI Win32_PerfRawData_DirectoryServices_DirectoryServices metrics
 type ADCollector struct {
 	AddressBookOperationsTotal                          *prometheus.Desc
@@ -199,7 +198,7 @@ func NewADCollector() (Collector, error) {
 		),
 		ReplicationPendingOperations: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "replication_pending_operations"),
-			"",
+			"Number of replication operations queued on the domain controller",
 			nil,
 			nil,
 		),
@@ -319,13 +318,13 @@ func NewADCollector() (Collector, error) {
 		),
 		LdapLastBindTimeSeconds: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "ldap_last_bind_time_seconds"),
-			"",
+			"Time taken by the last successful LDAP bind",
 			nil,
 			nil,
 		),
 		LdapSearchesTotal: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "ldap_searches_total"),
-			"",
+			"Total number of LDAP search operations",
 			nil,
 			nil,
 		),
@@ -433,7 +432,7 @@ func NewADCollector() (Collector, error) {
 		),
 		SamPasswordChangesTotal: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "sam_password_changes_total"),
-			"",
+			"Total number of password changes handled by the Security Accounts Manager",
 			nil,
 			nil,
 		),
@@ -456,7 +455,7 @@ func NewADCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *ADCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting ad metrics:", desc, err)
+		adLog.Error("failed collecting ad metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -617,11 +616,17 @@ type Win32_PerfRawData_DirectoryServices_DirectoryServices struct {
 func (c *ADCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_DirectoryServices_DirectoryServices
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
+		// The DirectoryServices counters only exist on domain controllers.
+		if isWMINotFoundError(err) {
+			adLog.Debugf("DirectoryServices counters not available, not a domain controller? %v. Skipping", err)
+			return nil, nil
+		}
 		return nil, err
 	}
 	if len(dst) == 0 {
-		return nil, errors.New("WMI query returned empty result set")
+		adLog.Debug("DirectoryServices counters returned no instance, not a domain controller? Skipping")
+		return nil, nil
 	}
 
 	ch <- prometheus.MustNewConstMetric(
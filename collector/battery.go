@@ -0,0 +1,92 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/headers/winbase"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("battery", newBatteryCollector)
+}
+
+var batteryLog = log.With("collector", "battery")
+
+// A BatteryCollector is a Prometheus collector for the system power status,
+// which includes the batteries of laptops and UPS connected to servers
+type BatteryCollector struct {
+	ChargePercent           *prometheus.Desc
+	OnACPower               *prometheus.Desc
+	EstimatedRuntimeSeconds *prometheus.Desc
+}
+
+func newBatteryCollector() (Collector, error) {
+	const subsystem = "battery"
+
+	return &BatteryCollector{
+		ChargePercent: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "charge_percent"),
+			"Remaining charge of the battery, in percent (BatteryLifePercent)",
+			nil,
+			nil,
+		),
+		OnACPower: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "on_ac_power"),
+			"Whether the system runs on AC power (ACLineStatus)",
+			nil,
+			nil,
+		),
+		EstimatedRuntimeSeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "estimated_runtime_seconds"),
+			"Estimated remaining runtime on battery power, in seconds. Only known while discharging (BatteryLifeTime)",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *BatteryCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		batteryLog.Error("failed collecting battery metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+func (c *BatteryCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	status, err := winbase.GetSystemPowerStatus()
+	if err != nil {
+		return nil, err
+	}
+	if status.BatteryFlag == winbase.BatteryFlagNoBattery {
+		batteryLog.Debug("No system battery found. Skipping")
+		return nil, nil
+	}
+
+	if status.ACLineStatus != winbase.ACLineStatusUnknown {
+		ch <- prometheus.MustNewConstMetric(
+			c.OnACPower,
+			prometheus.GaugeValue,
+			boolToFloat(status.ACLineStatus == winbase.ACLineStatusOnline),
+		)
+	}
+	if status.BatteryLifePercent != winbase.BatteryLifePercentUnknown {
+		ch <- prometheus.MustNewConstMetric(
+			c.ChargePercent,
+			prometheus.GaugeValue,
+			float64(status.BatteryLifePercent),
+		)
+	}
+	if status.BatteryLifeTime != winbase.BatteryLifeTimeUnknown {
+		ch <- prometheus.MustNewConstMetric(
+			c.EstimatedRuntimeSeconds,
+			prometheus.GaugeValue,
+			float64(status.BatteryLifeTime),
+		)
+	}
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkBatteryCollector(b *testing.B) {
+	benchmarkCollector(b, "battery", newBatteryCollector)
+}
@@ -0,0 +1,208 @@
+// +build windows
+
+package collector
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("bitlocker", newBitlockerCollector)
+}
+
+var bitlockerLog = log.With("collector", "bitlocker")
+
+const bitlockerNamespace = `root\CIMV2\Security\MicrosoftVolumeEncryption`
+
+var bitlockerProtectionStatuses = []string{"off", "on", "unknown"}
+
+// A bitlockerCollector is a Prometheus collector for WMI Win32_EncryptableVolume metrics
+type bitlockerCollector struct {
+	ProtectionStatus     *prometheus.Desc
+	EncryptionPercentage *prometheus.Desc
+	LockStatus           *prometheus.Desc
+
+	accessDeniedOnce sync.Once
+}
+
+func newBitlockerCollector() (Collector, error) {
+	const subsystem = "bitlocker"
+	return &bitlockerCollector{
+		ProtectionStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "protection_status"),
+			"The BitLocker protection status of the volume (ProtectionStatus)",
+			[]string{"mount", "status"},
+			nil,
+		),
+		EncryptionPercentage: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "encryption_percentage"),
+			"Percentage of the volume which is encrypted (GetConversionStatus.EncryptionPercentage)",
+			[]string{"mount"},
+			nil,
+		),
+		LockStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "lock_status"),
+			"Whether the volume is locked, 1 when it is (GetLockStatus.LockStatus)",
+			[]string{"mount"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *bitlockerCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		bitlockerLog.Error("failed collecting bitlocker metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+type bitlockerVolume struct {
+	Mount            string
+	ProtectionStatus uint32
+	// Nil when the method reading them failed for the volume.
+	EncryptionPercentage *uint32
+	LockStatus           *uint32
+}
+
+func (c *bitlockerCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	volumes, err := queryBitlockerVolumes()
+	switch {
+	case isWMINotFoundError(err):
+		bitlockerLog.Debugf("BitLocker WMI classes not found, BitLocker is likely not installed. Skipping: %v", err)
+		return nil, nil
+	case isWMIAccessDeniedError(err):
+		c.accessDeniedOnce.Do(func() {
+			bitlockerLog.Warnf("Access to %s was denied, the bitlocker collector requires administrator rights: %v", bitlockerNamespace, err)
+		})
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	for _, volume := range volumes {
+		status := bitlockerProtectionStatus(volume.ProtectionStatus)
+		for _, s := range bitlockerProtectionStatuses {
+			isCurrent := 0.0
+			if s == status {
+				isCurrent = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.ProtectionStatus,
+				prometheus.GaugeValue,
+				isCurrent,
+				volume.Mount,
+				s,
+			)
+		}
+
+		if volume.EncryptionPercentage != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.EncryptionPercentage,
+				prometheus.GaugeValue,
+				float64(*volume.EncryptionPercentage),
+				volume.Mount,
+			)
+		}
+
+		if volume.LockStatus != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.LockStatus,
+				prometheus.GaugeValue,
+				float64(*volume.LockStatus),
+				volume.Mount,
+			)
+		}
+	}
+
+	return nil, nil
+}
+
+// queryBitlockerVolumes lists the encryptable volumes along with the status
+// returned by their GetConversionStatus and GetLockStatus methods. Calling
+// methods isn't supported by the wmi package, so this goes through the
+// scripting API directly.
+func queryBitlockerVolumes() ([]bitlockerVolume, error) {
+	var volumes []bitlockerVolume
+	err := oleQueryNamespace(bitlockerNamespace, "SELECT DeviceID, DriveLetter, ProtectionStatus FROM Win32_EncryptableVolume", func(item *ole.IDispatch) error {
+		deviceID, err := oleString(item, "DeviceID")
+		if err != nil {
+			return err
+		}
+		driveLetter, err := oleString(item, "DriveLetter")
+		if err != nil {
+			return err
+		}
+		protectionStatus, err := oleUint32(item, "ProtectionStatus")
+		if err != nil {
+			return err
+		}
+
+		volume := bitlockerVolume{
+			Mount:            bitlockerMount(driveLetter, deviceID),
+			ProtectionStatus: protectionStatus,
+		}
+		if v, err := callBitlockerMethod(item, "GetConversionStatus", "EncryptionPercentage"); err != nil {
+			bitlockerLog.Debugf("Could not get the conversion status of volume %s: %v", volume.Mount, err)
+		} else {
+			volume.EncryptionPercentage = &v
+		}
+		if v, err := callBitlockerMethod(item, "GetLockStatus", "LockStatus"); err != nil {
+			bitlockerLog.Debugf("Could not get the lock status of volume %s: %v", volume.Mount, err)
+		} else {
+			volume.LockStatus = &v
+		}
+		volumes = append(volumes, volume)
+		return nil
+	})
+	return volumes, err
+}
+
+// callBitlockerMethod calls method on the volume, and returns the property
+// of its output parameters.
+func callBitlockerMethod(volume *ole.IDispatch, method string, property string) (uint32, error) {
+	outRaw, err := oleutil.CallMethod(volume, "ExecMethod_", method)
+	if err != nil {
+		return 0, err
+	}
+	defer outRaw.Clear()
+	out := outRaw.ToIDispatch()
+
+	rv, err := oleUint32(out, "ReturnValue")
+	if err != nil {
+		return 0, err
+	}
+	if rv != 0 {
+		return 0, fmt.Errorf("%s returned 0x%08X", method, rv)
+	}
+	return oleUint32(out, property)
+}
+
+// bitlockerProtectionStatus maps the ProtectionStatus property to the status
+// label.
+func bitlockerProtectionStatus(code uint32) string {
+	switch code {
+	case 0:
+		return "off"
+	case 1:
+		return "on"
+	}
+	return "unknown"
+}
+
+// bitlockerMount returns the drive letter of the volume, or its device ID
+// when it has none.
+func bitlockerMount(driveLetter string, deviceID string) string {
+	if driveLetter != "" {
+		return driveLetter
+	}
+	return deviceID
+}
@@ -0,0 +1,33 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkBitlockerCollector(b *testing.B) {
+	benchmarkCollector(b, "bitlocker", newBitlockerCollector)
+}
+
+func TestBitlockerProtectionStatus(t *testing.T) {
+	cases := map[uint32]string{
+		0: "off",
+		1: "on",
+		2: "unknown",
+		7: "unknown",
+	}
+	for code, expected := range cases {
+		if got := bitlockerProtectionStatus(code); got != expected {
+			t.Errorf("bitlockerProtectionStatus(%d) = %q, expected %q", code, got, expected)
+		}
+	}
+}
+
+func TestBitlockerMount(t *testing.T) {
+	const deviceID = `\\?\Volume{3c2e1a57-0000-0000-0000-100000000000}\`
+	if got := bitlockerMount("C:", deviceID); got != "C:" {
+		t.Errorf("expected the drive letter, got %q", got)
+	}
+	if got := bitlockerMount("", deviceID); got != deviceID {
+		t.Errorf("expected the device ID, got %q", got)
+	}
+}
@@ -11,6 +11,8 @@ func init() {
 	registerCollector("cache", newCacheCollector, "Cache")
 }
 
+var cacheLog = log.With("collector", "cache")
+
 // A CacheCollector is a Prometheus collector for Perflib Cache metrics
 type CacheCollector struct {
 	AsyncCopyReadsTotal         *prometheus.Desc
@@ -228,7 +230,7 @@ func newCacheCollector() (Collector, error) {
 // Collect implements the Collector interface
 func (c *CacheCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting cache metrics:", desc, err)
+		cacheLog.Error("failed collecting cache metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -0,0 +1,104 @@
+// +build windows
+
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// A cachedCollector wraps a collector whose data is expensive to gather and
+// rarely changes, replaying the metrics of its last successful collection
+// until they are older than the TTL.
+//
+// Replayed metrics don't carry the timestamp of the collection: Prometheus
+// would otherwise consider them stale once it falls behind its lookback
+// delta. windows_exporter_collector_cache_age_seconds exposes how old they
+// are instead.
+type cachedCollector struct {
+	name      string
+	collector Collector
+	ttl       time.Duration
+	now       func() time.Time
+
+	CacheAge *prometheus.Desc
+
+	mu          sync.Mutex
+	metrics     []prometheus.Metric
+	collectedAt time.Time
+}
+
+func newCachedCollector(name string, c Collector, ttl time.Duration) *cachedCollector {
+	return &cachedCollector{
+		name:      name,
+		collector: c,
+		ttl:       ttl,
+		now:       time.Now,
+		CacheAge: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "exporter", "collector_cache_age_seconds"),
+			"windows_exporter: Age of the metrics served from the cache of a collector.",
+			[]string{"collector"},
+			nil,
+		),
+	}
+}
+
+// Describe sends the descriptors of the wrapped collector, and the cache age.
+func (c *cachedCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range Describe(c.collector) {
+		ch <- desc
+	}
+	ch <- c.CacheAge
+}
+
+// Collect sends the cached metrics if they are still fresh, or refreshes them
+// otherwise. Failed collections aren't cached, so they are retried on the
+// next scrape.
+func (c *cachedCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	// Concurrent scrapes wait for a refresh in progress rather than
+	// refreshing again.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.metrics == nil || now.Sub(c.collectedAt) >= c.ttl {
+		metrics, err := c.refresh(ctx, ch)
+		if err != nil {
+			return err
+		}
+		c.metrics = metrics
+		c.collectedAt = now
+	} else {
+		for _, m := range c.metrics {
+			ch <- m
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.CacheAge,
+		prometheus.GaugeValue,
+		now.Sub(c.collectedAt).Seconds(),
+		c.name,
+	)
+	return nil
+}
+
+// refresh runs the wrapped collector, forwarding its metrics to ch while
+// recording them.
+func (c *cachedCollector) refresh(ctx *ScrapeContext, ch chan<- prometheus.Metric) ([]prometheus.Metric, error) {
+	buf := make(chan prometheus.Metric)
+	var err error
+	go func() {
+		err = c.collector.Collect(ctx, buf)
+		close(buf)
+	}()
+
+	metrics := []prometheus.Metric{}
+	for m := range buf {
+		metrics = append(metrics, m)
+		ch <- m
+	}
+	return metrics, err
+}
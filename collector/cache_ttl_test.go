@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type countingCollector struct {
+	desc  *prometheus.Desc
+	calls int
+	err   error
+}
+
+func (c *countingCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	c.calls++
+	if c.err != nil {
+		return c.err
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(c.calls))
+	return nil
+}
+
+func collectCached(t *testing.T, c *cachedCollector) (float64, int, error) {
+	ch := make(chan prometheus.Metric, 10)
+	err := c.Collect(&ScrapeContext{}, ch)
+	close(ch)
+
+	var value float64
+	n := 0
+	for m := range ch {
+		n++
+		if m.Desc() == c.CacheAge {
+			continue
+		}
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil {
+			t.Fatal(err)
+		}
+		value = pb.GetGauge().GetValue()
+	}
+	return value, n, err
+}
+
+func TestCachedCollector(t *testing.T) {
+	inner := &countingCollector{desc: prometheus.NewDesc("test_calls", "", nil, nil)}
+	c := newCachedCollector("test", inner, time.Minute)
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+
+	if v, n, err := collectCached(t, c); err != nil || v != 1 || n != 2 {
+		t.Errorf("first collection: got value %v, %d metrics, %v", v, n, err)
+	}
+	now = now.Add(30 * time.Second)
+	if v, _, _ := collectCached(t, c); v != 1 || inner.calls != 1 {
+		t.Errorf("expected the cached value before the TTL, got %v after %d calls", v, inner.calls)
+	}
+	now = now.Add(30 * time.Second)
+	if v, _, _ := collectCached(t, c); v != 2 || inner.calls != 2 {
+		t.Errorf("expected a refresh after the TTL, got %v after %d calls", v, inner.calls)
+	}
+
+	// Failures are not cached, and keep the previous metrics.
+	inner.err = errors.New("failed")
+	now = now.Add(time.Minute)
+	if _, _, err := collectCached(t, c); err == nil {
+		t.Error("expected the error of the wrapped collector")
+	}
+	inner.err = nil
+	if v, _, err := collectCached(t, c); err != nil || v != 4 {
+		t.Errorf("expected a refresh after a failure, got %v, %v", v, err)
+	}
+}
@@ -2,21 +2,43 @@ package collector
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unsafe"
 
 	"github.com/leoluk/perflib_exporter/perflib"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sys/windows/registry"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
+// Namespace is the prefix of the names of all metrics. Override it with
+// SetNamespace.
+var Namespace = "windows"
+
+// metricNamespaceRE matches a valid metric name segment. Colons are valid in
+// metric names but reserved for recording rules.
+var metricNamespaceRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// SetNamespace overrides the prefix of the names of all metrics. It must be
+// called before any collector is built.
+func SetNamespace(namespace string) error {
+	if !metricNamespaceRE.MatchString(namespace) {
+		return fmt.Errorf("invalid metric namespace %q, must match %s", namespace, metricNamespaceRE)
+	}
+	Namespace = namespace
+	WMIRetriesTotal = newWMIRetriesTotal()
+	WMIResultRows = newWMIResultRows()
+	return nil
+}
+
 // ...
 const (
-	// TODO: Make package-local
-	Namespace = "windows"
-
 	// Conversion factors
 	ticksToSecondsScaleFactor = 1 / 1e7
 	windowsEpoch              = 116444736000000000
@@ -55,11 +77,41 @@ type collectorBuilder func() (Collector, error)
 var (
 	builders                = make(map[string]collectorBuilder)
 	perfCounterDependencies = make(map[string]string)
+	cacheTTLs               = make(map[string]*time.Duration)
+
+	// Errors encountered while registering collectors at init time, reported
+	// by RegistrationError.
+	registrationErrors []string
+
+	useSourceTimestamps = kingpin.Flag(
+		"collectors.use-source-timestamps",
+		"Expose metrics with the time their data source sampled them rather than the scrape time, for the collectors supporting it (perfcounter).",
+	).Default("false").Bool()
 )
 
+// registerCollector makes a collector available under name. Collectors log
+// through a logger adding a collector field to their lines, declared next to
+// the call, e.g. var cpuLog = log.With("collector", "cpu").
 func registerCollector(name string, builder collectorBuilder, perfCounterNames ...string) {
+	if _, exists := builders[name]; exists {
+		registrationErrors = append(registrationErrors, fmt.Sprintf("collector %q is registered more than once", name))
+		return
+	}
 	builders[name] = builder
 	addPerfCounterDependencies(name, perfCounterNames)
+	cacheTTLs[name] = kingpin.Flag(
+		fmt.Sprintf("collector.%s.cache-ttl", name),
+		fmt.Sprintf("Serve the metrics of the %s collector from a cache refreshed at this interval. 0 to disable.", name),
+	).Default("0s").Duration()
+}
+
+// RegistrationError returns an error if some collectors couldn't be
+// registered, e.g. because of duplicate names.
+func RegistrationError() error {
+	if len(registrationErrors) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed to register collectors: %s", strings.Join(registrationErrors, "; "))
 }
 
 func addPerfCounterDependencies(name string, perfCounterNames []string) {
@@ -77,12 +129,30 @@ func Available() []string {
 	}
 	return cs
 }
+
+// Collectors returns the names of the registered collectors, sorted.
+func Collectors() []string {
+	cs := Available()
+	sort.Strings(cs)
+	return cs
+}
+
 func Build(collector string) (Collector, error) {
 	builder, exists := builders[collector]
 	if !exists {
 		return nil, fmt.Errorf("Unknown collector %q", collector)
 	}
-	return builder()
+	c, err := builder()
+	if err != nil {
+		return nil, err
+	}
+	switch ttl := *cacheTTLs[collector]; {
+	case ttl < 0:
+		return nil, fmt.Errorf("collector.%s.cache-ttl must not be negative", collector)
+	case ttl > 0:
+		return newCachedCollector(collector, c, ttl), nil
+	}
+	return c, nil
 }
 func getPerfQuery(collectors []string) string {
 	parts := make([]string, 0, len(collectors))
@@ -100,8 +170,122 @@ type Collector interface {
 	Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (err error)
 }
 
+var (
+	descType         = reflect.TypeOf((*prometheus.Desc)(nil))
+	collectorPkgPath = reflect.TypeOf(ScrapeContext{}).PkgPath()
+)
+
+// Describer is implemented by collectors which describe their metrics
+// themselves, e.g. because some of their descriptor fields are alternatives
+// which are never exposed together.
+type Describer interface {
+	Describe(ch chan<- *prometheus.Desc)
+}
+
+// Describe returns the descriptors of the metrics c emits. Collectors
+// implementing Describer are asked for them. For the others, they are found
+// by walking the fields of c, in the order they are declared, following the
+// collector types of this package which it wraps.
+func Describe(c Collector) []*prometheus.Desc {
+	var descs []*prometheus.Desc
+	if d, ok := c.(Describer); ok {
+		ch := make(chan *prometheus.Desc)
+		go func() {
+			d.Describe(ch)
+			close(ch)
+		}()
+		for desc := range ch {
+			descs = append(descs, desc)
+		}
+		return descs
+	}
+
+	seen := map[uintptr]bool{}
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() || seen[v.Pointer()] {
+				return
+			}
+			seen[v.Pointer()] = true
+			if v.Type() == descType {
+				// Fields may be unexported, which rules out v.Interface().
+				descs = append(descs, (*prometheus.Desc)(unsafe.Pointer(v.Pointer())))
+				return
+			}
+			if v.Type().Elem().PkgPath() == collectorPkgPath {
+				walk(v.Elem())
+			}
+		case reflect.Interface:
+			if !v.IsNil() {
+				walk(v.Elem())
+			}
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				walk(v.Field(i))
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i))
+			}
+		case reflect.Map:
+			keys := v.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+			for _, k := range keys {
+				walk(v.MapIndex(k))
+			}
+		}
+	}
+	walk(reflect.ValueOf(c))
+	return descs
+}
+
+// descCollector registers fixed descriptors, to have a registry check them.
+type descCollector []*prometheus.Desc
+
+func (d descCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range d {
+		ch <- desc
+	}
+}
+
+func (d descCollector) Collect(ch chan<- prometheus.Metric) {}
+
+// CheckDescriptors registers the descriptors of the collectors with a
+// registry, which reports invalid descriptors, and descriptors sharing a name
+// but not their help or labels. Otherwise these only surface when the
+// metrics are built, during a scrape. Identical descriptors of several
+// collectors, e.g. the cache age of cached collectors, are accepted.
+func CheckDescriptors(collectors map[string]Collector) error {
+	names := make([]string, 0, len(collectors))
+	for name := range collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reg := prometheus.NewRegistry()
+	seen := map[string]bool{}
+	for _, name := range names {
+		var descs descCollector
+		for _, desc := range Describe(collectors[name]) {
+			if !seen[desc.String()] {
+				seen[desc.String()] = true
+				descs = append(descs, desc)
+			}
+		}
+		if err := reg.Register(descs); err != nil {
+			return fmt.Errorf("collector %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
 type ScrapeContext struct {
 	perfObjects map[string]*perflib.PerfObject
+	// SourceTimestamps is set when collectors should expose metrics with the
+	// time their data source sampled them, where it is available.
+	SourceTimestamps bool
 }
 
 // PrepareScrapeContext creates a ScrapeContext to be used during a single scrape
@@ -112,7 +296,7 @@ func PrepareScrapeContext(collectors []string) (*ScrapeContext, error) {
 		return nil, err
 	}
 
-	return &ScrapeContext{objs}, nil
+	return &ScrapeContext{perfObjects: objs, SourceTimestamps: *useSourceTimestamps}, nil
 }
 func boolToFloat(b bool) float64 {
 	if b {
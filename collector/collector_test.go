@@ -3,6 +3,7 @@ package collector
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -35,6 +36,26 @@ func TestExpandChildCollectors(t *testing.T) {
 	}
 }
 
+func TestRegisterCollectorDuplicate(t *testing.T) {
+	const name = "test_duplicate"
+	defer func() {
+		delete(builders, name)
+		delete(perfCounterDependencies, name)
+		registrationErrors = nil
+	}()
+
+	builder := func() (Collector, error) { return nil, nil }
+	registerCollector(name, builder)
+	if err := RegistrationError(); err != nil {
+		t.Fatalf("unexpected registration error: %v", err)
+	}
+
+	registerCollector(name, builder)
+	if err := RegistrationError(); err == nil {
+		t.Error("expected a registration error for a duplicate collector")
+	}
+}
+
 func benchmarkCollector(b *testing.B, name string, collectFunc func() (Collector, error)) {
 	// Create perflib scrape context. Some perflib collectors required a correct context,
 	// or will fail during benchmark.
@@ -58,3 +79,152 @@ func benchmarkCollector(b *testing.B, name string, collectFunc func() (Collector
 		c.Collect(scrapeContext, metrics)
 	}
 }
+
+func TestSetNamespace(t *testing.T) {
+	defer func(namespace string) {
+		if err := SetNamespace(namespace); err != nil {
+			t.Fatal(err)
+		}
+	}(Namespace)
+
+	for _, invalid := range []string{"", "1windows", "windows-os", "windows:os"} {
+		if err := SetNamespace(invalid); err == nil {
+			t.Errorf("expected an error for namespace %q", invalid)
+		}
+	}
+
+	if err := SetNamespace("win_os"); err != nil {
+		t.Fatal(err)
+	}
+	if name := prometheus.BuildFQName(Namespace, "cpu", "time_total"); name != "win_os_cpu_time_total" {
+		t.Errorf("expected metric name win_os_cpu_time_total, got %s", name)
+	}
+}
+
+type describeTestCollector struct {
+	Exported   *prometheus.Desc
+	unexported *prometheus.Desc
+	byState    map[string]*prometheus.Desc
+	missing    *prometheus.Desc
+	shared     *prometheus.Desc
+}
+
+func (c *describeTestCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	return nil
+}
+
+func TestDescribe(t *testing.T) {
+	newDesc := func(name string) *prometheus.Desc {
+		return prometheus.NewDesc(name, "help", nil, nil)
+	}
+	c := &describeTestCollector{
+		Exported:   newDesc("exported"),
+		unexported: newDesc("unexported"),
+		byState: map[string]*prometheus.Desc{
+			"stopped": newDesc("stopped"),
+			"running": newDesc("running"),
+		},
+	}
+	c.shared = c.Exported
+	cached := newCachedCollector("test", c, time.Minute)
+
+	expected := []*prometheus.Desc{c.Exported, c.unexported, c.byState["running"], c.byState["stopped"]}
+	if got := Describe(c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	expected = append(expected, cached.CacheAge)
+	if got := Describe(cached); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+type describerTestCollector struct {
+	Used    *prometheus.Desc
+	Ignored *prometheus.Desc
+}
+
+func (c *describerTestCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.Used
+}
+
+func (c *describerTestCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	return nil
+}
+
+func TestDescribeDescriber(t *testing.T) {
+	c := &describerTestCollector{
+		Used:    prometheus.NewDesc("test_state", "help", []string{"state"}, nil),
+		Ignored: prometheus.NewDesc("test_state", "help", []string{"other"}, nil),
+	}
+	expected := []*prometheus.Desc{c.Used}
+	if got := Describe(c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := Describe(newCachedCollector("test", c, time.Minute)); len(got) != 2 || got[0] != c.Used {
+		t.Errorf("expected the descriptor of the wrapped collector and the cache age, got %v", got)
+	}
+}
+
+func TestCheckDescriptors(t *testing.T) {
+	newCollector := func(descs ...*prometheus.Desc) Collector {
+		c := &describeTestCollector{Exported: descs[0]}
+		if len(descs) > 1 {
+			c.unexported = descs[1]
+		}
+		return c
+	}
+	cases := []struct {
+		name       string
+		collectors map[string]Collector
+		valid      bool
+	}{
+		{
+			name: "distinct",
+			collectors: map[string]Collector{
+				"a": newCollector(prometheus.NewDesc("a_info", "help", []string{"name"}, nil)),
+				"b": newCollector(prometheus.NewDesc("b_info", "help", []string{"name"}, nil)),
+			},
+			valid: true,
+		},
+		{
+			name: "identical across collectors",
+			collectors: map[string]Collector{
+				"a": newCollector(prometheus.NewDesc("cache_age", "help", []string{"collector"}, nil)),
+				"b": newCollector(prometheus.NewDesc("cache_age", "help", []string{"collector"}, nil)),
+			},
+			valid: true,
+		},
+		{
+			name: "different labels",
+			collectors: map[string]Collector{
+				"a": newCollector(
+					prometheus.NewDesc("a_state", "help", []string{"state"}, nil),
+					prometheus.NewDesc("a_state", "help", []string{"status"}, nil),
+				),
+			},
+			valid: false,
+		},
+		{
+			name: "different help across collectors",
+			collectors: map[string]Collector{
+				"a": newCollector(prometheus.NewDesc("shared", "help", nil, nil)),
+				"b": newCollector(prometheus.NewDesc("shared", "other help", nil, nil)),
+			},
+			valid: false,
+		},
+		{
+			name: "invalid label name",
+			collectors: map[string]Collector{
+				"a": newCollector(prometheus.NewDesc("a_info", "help", []string{"not-valid"}, nil)),
+			},
+			valid: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := CheckDescriptors(c.collectors); (err == nil) != c.valid {
+				t.Errorf("expected valid %t, got error %v", c.valid, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@
 package collector
 
 import (
+	"strings"
+
 	"github.com/Microsoft/hcsshim"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,10 +14,13 @@ func init() {
 	registerCollector("container", NewContainerMetricsCollector)
 }
 
+var containerLog = log.With("collector", "container")
+
 // A ContainerMetricsCollector is a Prometheus collector for containers metrics
 type ContainerMetricsCollector struct {
 	// Presence
 	ContainerAvailable *prometheus.Desc
+	State              *prometheus.Desc
 
 	// Number of containers
 	ContainersCount *prometheus.Desc
@@ -48,6 +53,12 @@ func NewContainerMetricsCollector() (Collector, error) {
 			[]string{"container_id"},
 			nil,
 		),
+		State: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "state"),
+			"The state of the container compute system, 1 if the current state, 0 otherwise",
+			[]string{"container_id", "state"},
+			nil,
+		),
 		ContainersCount: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "count"),
 			"Number of containers",
@@ -133,7 +144,7 @@ func NewContainerMetricsCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *ContainerMetricsCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting ContainerMetricsCollector metrics:", desc, err)
+		containerLog.Error("failed collecting ContainerMetricsCollector metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -143,7 +154,7 @@ func (c *ContainerMetricsCollector) Collect(ctx *ScrapeContext, ch chan<- promet
 func containerClose(c hcsshim.Container) {
 	err := c.Close()
 	if err != nil {
-		log.Error(err)
+		containerLog.Error(err)
 	}
 }
 
@@ -152,7 +163,7 @@ func (c *ContainerMetricsCollector) collect(ch chan<- prometheus.Metric) (*prome
 	// Types Container is passed to get the containers compute systems only
 	containers, err := hcsshim.GetContainers(hcsshim.ComputeSystemQuery{Types: []string{"Container"}})
 	if err != nil {
-		log.Error("Err in Getting containers:", err)
+		containerLog.Error("Err in Getting containers:", err)
 		return nil, err
 	}
 
@@ -168,18 +179,31 @@ func (c *ContainerMetricsCollector) collect(ch chan<- prometheus.Metric) (*prome
 	}
 
 	for _, containerDetails := range containers {
+		// The state is exposed even for containers whose statistics can't be
+		// fetched, e.g. because they are stopped.
+		state := containerState(containerDetails.State)
+		for _, s := range allContainerStates {
+			ch <- prometheus.MustNewConstMetric(
+				c.State,
+				prometheus.GaugeValue,
+				boolToFloat(s == state),
+				getContainerIdWithPrefix(containerDetails),
+				s,
+			)
+		}
+
 		container, err := hcsshim.OpenContainer(containerDetails.ID)
 		if container != nil {
 			defer containerClose(container)
 		}
 		if err != nil {
-			log.Error("err in opening container: ", containerDetails.ID, err)
+			containerLog.Error("err in opening container: ", containerDetails.ID, err)
 			continue
 		}
 
 		cstats, err := container.Statistics()
 		if err != nil {
-			log.Error("err in fetching container Statistics: ", containerDetails.ID, err)
+			containerLog.Error("err in fetching container Statistics: ", containerDetails.ID, err)
 			continue
 		}
 		containerIdWithPrefix := getContainerIdWithPrefix(containerDetails)
@@ -228,7 +252,7 @@ func (c *ContainerMetricsCollector) collect(ch chan<- prometheus.Metric) (*prome
 		)
 
 		if len(cstats.Network) == 0 {
-			log.Info("No Network Stats for container: ", containerDetails.ID)
+			containerLog.Info("No Network Stats for container: ", containerDetails.ID)
 			continue
 		}
 
@@ -278,6 +302,21 @@ func (c *ContainerMetricsCollector) collect(ch chan<- prometheus.Metric) (*prome
 	return nil, nil
 }
 
+// allContainerStates lists the states of HCS compute systems, lowercased.
+var allContainerStates = []string{"created", "running", "paused", "stopped", "savedastemplate", "unknown"}
+
+// containerState returns the label value of the state of a compute system,
+// "unknown" for any state not in allContainerStates.
+func containerState(state string) string {
+	state = strings.ToLower(state)
+	for _, s := range allContainerStates {
+		if s == state {
+			return s
+		}
+	}
+	return "unknown"
+}
+
 func getContainerIdWithPrefix(containerDetails hcsshim.ContainerProperties) string {
 	switch containerDetails.Owner {
 	case "containerd-shim-runhcs-v1.exe":
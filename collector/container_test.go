@@ -4,6 +4,21 @@ import (
 	"testing"
 )
 
+func TestContainerState(t *testing.T) {
+	cases := map[string]string{
+		"Running": "running",
+		"Stopped": "stopped",
+		"Paused":  "paused",
+		"":        "unknown",
+		"Pausing": "unknown",
+	}
+	for state, want := range cases {
+		if got := containerState(state); got != want {
+			t.Errorf("containerState(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
+
 func BenchmarkContainerCollector(b *testing.B) {
 	benchmarkCollector(b, "container", NewContainerMetricsCollector)
 }
@@ -0,0 +1,103 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("cpu_detail", newCPUDetailCollector, "Processor")
+}
+
+// A cpuDetailCollector is a Prometheus collector for the interrupt and DPC
+// perflib Processor metrics of each logical processor, and of their total
+type cpuDetailCollector struct {
+	InterruptsTotal           *prometheus.Desc
+	DPCsTotal                 *prometheus.Desc
+	InterruptTimeSecondsTotal *prometheus.Desc
+	DPCTimeSecondsTotal       *prometheus.Desc
+}
+
+func newCPUDetailCollector() (Collector, error) {
+	// The cpu collector already exposes windows_cpu_interrupts_total and
+	// windows_cpu_dpcs_total, which these would clash with.
+	const subsystem = "cpu_detail"
+
+	return &cpuDetailCollector{
+		InterruptsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "interrupts_total"),
+			"Total number of received and serviced hardware interrupts (Interrupts/sec)",
+			[]string{"core"},
+			nil,
+		),
+		DPCsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "dpcs_total"),
+			"Total number of deferred procedure calls (DPCs) queued (DPCs Queued/sec)",
+			[]string{"core"},
+			nil,
+		),
+		InterruptTimeSecondsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "interrupt_time_seconds_total"),
+			"Time spent servicing hardware interrupts (% Interrupt Time)",
+			[]string{"core"},
+			nil,
+		),
+		DPCTimeSecondsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "dpc_time_seconds_total"),
+			"Time spent servicing deferred procedure calls (% DPC Time)",
+			[]string{"core"},
+			nil,
+		),
+	}, nil
+}
+
+type perflibProcessorDetail struct {
+	Name                 string
+	DPCsQueued           float64 `perflib:"DPCs Queued/sec"`
+	Interrupts           float64 `perflib:"Interrupts/sec"`
+	PercentDPCTime       float64 `perflib:"% DPC Time"`
+	PercentInterruptTime float64 `perflib:"% Interrupt Time"`
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *cpuDetailCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	data := make([]perflibProcessorDetail, 0)
+	err := unmarshalObject(ctx.perfObjects["Processor"], &data)
+	if err != nil {
+		return err
+	}
+
+	// Unlike the cpu collector, the _Total instance is kept.
+	for _, cpu := range data {
+		core := cpu.Name
+
+		ch <- prometheus.MustNewConstMetric(
+			c.InterruptsTotal,
+			prometheus.CounterValue,
+			cpu.Interrupts,
+			core,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.DPCsTotal,
+			prometheus.CounterValue,
+			cpu.DPCsQueued,
+			core,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.InterruptTimeSecondsTotal,
+			prometheus.CounterValue,
+			cpu.PercentInterruptTime,
+			core,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.DPCTimeSecondsTotal,
+			prometheus.CounterValue,
+			cpu.PercentDPCTime,
+			core,
+		)
+	}
+
+	return nil
+}
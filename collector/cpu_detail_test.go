@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkCPUDetailCollector(b *testing.B) {
+	benchmarkCollector(b, "cpu_detail", newCPUDetailCollector)
+}
@@ -7,7 +7,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -16,6 +15,8 @@ func init() {
 	registerCollector("cpu_info", newCpuInfoCollector)
 }
 
+var cpuInfoLog = log.With("collector", "cpu_info")
+
 // If you are adding additional labels to the metric, make sure that they get added in here as well. See below for explanation.
 const (
 	win32ProcessorQuery = "SELECT Architecture, DeviceId, Description, Family, L2CacheSize, L3CacheSize, Name FROM Win32_Processor"
@@ -58,7 +59,7 @@ type win32_Processor struct {
 // to the provided prometheus Metric channel.
 func (c *CpuInfoCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting cpu_info metrics:", desc, err)
+		cpuInfoLog.Error("failed collecting cpu_info metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -69,7 +70,7 @@ func (c *CpuInfoCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Des
 	// We use a static query here because the provided methods in wmi.go all issue a SELECT *;
 	// This results in the time consuming LoadPercentage field being read which seems to measure each CPU
 	// serially over a 1 second interval, so the scrape time is at least 1s * num_sockets
-	if err := wmi.Query(win32ProcessorQuery, &dst); err != nil {
+	if err := wmiQuery(win32ProcessorQuery, &dst); err != nil {
 		return nil, err
 	}
 	if len(dst) == 0 {
@@ -0,0 +1,128 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+func init() {
+	registerCollector("crashdump", newCrashDumpCollector)
+}
+
+var crashdumpLog = log.With("collector", "crashdump")
+
+const crashControlKey = `SYSTEM\CurrentControlSet\Control\CrashControl`
+
+var allCrashDumpTypes = []string{"none", "complete", "active", "kernel", "small", "automatic"}
+
+// crashDumpType maps the CrashDumpEnabled value to a label value. An active
+// memory dump is a complete memory dump with FilterPages set.
+func crashDumpType(enabled, filterPages uint64) string {
+	switch enabled {
+	case 0:
+		return "none"
+	case 1:
+		if filterPages == 1 {
+			return "active"
+		}
+		return "complete"
+	case 2:
+		return "kernel"
+	case 3:
+		return "small"
+	case 7:
+		return "automatic"
+	}
+	return ""
+}
+
+// A CrashDumpCollector is a Prometheus collector for the crash dump settings,
+// read from the CrashControl registry key
+type CrashDumpCollector struct {
+	Enabled    *prometheus.Desc
+	Type       *prometheus.Desc
+	AutoReboot *prometheus.Desc
+}
+
+func newCrashDumpCollector() (Collector, error) {
+	const subsystem = "crashdump"
+
+	return &CrashDumpCollector{
+		Enabled: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "enabled"),
+			"Whether a memory dump is written when the system stops unexpectedly (CrashDumpEnabled)",
+			nil,
+			nil,
+		),
+		Type: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "type"),
+			"The type of memory dump written when the system stops unexpectedly, 1 if the current type, 0 otherwise (CrashDumpEnabled)",
+			[]string{"type"},
+			nil,
+		),
+		AutoReboot: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "autoreboot"),
+			"Whether the system restarts automatically after it stops unexpectedly (AutoReboot)",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *CrashDumpCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		crashdumpLog.Error("failed collecting crashdump metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+func (c *CrashDumpCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, crashControlKey, registry.QUERY_VALUE)
+	if err != nil {
+		return c.Enabled, err
+	}
+	defer k.Close()
+
+	enabled, _, err := k.GetIntegerValue("CrashDumpEnabled")
+	if err != nil {
+		return c.Enabled, err
+	}
+	// FilterPages only exists when an active memory dump is configured.
+	filterPages, _, _ := k.GetIntegerValue("FilterPages")
+	autoReboot, _, err := k.GetIntegerValue("AutoReboot")
+	if err != nil {
+		return c.AutoReboot, err
+	}
+
+	dumpType := crashDumpType(enabled, filterPages)
+	if dumpType == "" {
+		crashdumpLog.Debugf("Unknown CrashDumpEnabled value %d", enabled)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Enabled,
+		prometheus.GaugeValue,
+		boolToFloat(enabled != 0),
+	)
+	for _, t := range allCrashDumpTypes {
+		ch <- prometheus.MustNewConstMetric(
+			c.Type,
+			prometheus.GaugeValue,
+			boolToFloat(t == dumpType),
+			t,
+		)
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.AutoReboot,
+		prometheus.GaugeValue,
+		boolToFloat(autoReboot != 0),
+	)
+
+	return nil, nil
+}
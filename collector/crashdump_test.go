@@ -0,0 +1,30 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkCrashDumpCollector(b *testing.B) {
+	benchmarkCollector(b, "crashdump", newCrashDumpCollector)
+}
+
+func TestCrashDumpType(t *testing.T) {
+	cases := []struct {
+		enabled, filterPages uint64
+		want                 string
+	}{
+		{0, 0, "none"},
+		{1, 0, "complete"},
+		{1, 1, "active"},
+		{2, 0, "kernel"},
+		{2, 1, "kernel"},
+		{3, 0, "small"},
+		{7, 0, "automatic"},
+		{5, 0, ""},
+	}
+	for _, tc := range cases {
+		if got := crashDumpType(tc.enabled, tc.filterPages); got != tc.want {
+			t.Errorf("crashDumpType(%d, %d) = %q, want %q", tc.enabled, tc.filterPages, got, tc.want)
+		}
+	}
+}
@@ -3,6 +3,8 @@
 package collector
 
 import (
+	"strings"
+
 	"github.com/prometheus-community/windows_exporter/headers/sysinfoapi"
 	"github.com/prometheus-community/windows_exporter/log"
 
@@ -13,11 +15,14 @@ func init() {
 	registerCollector("cs", NewCSCollector)
 }
 
+var csLog = log.With("collector", "cs")
+
 // A CSCollector is a Prometheus collector for WMI metrics
 type CSCollector struct {
 	PhysicalMemoryBytes *prometheus.Desc
 	LogicalProcessors   *prometheus.Desc
 	Hostname            *prometheus.Desc
+	Info                *prometheus.Desc
 }
 
 // NewCSCollector ...
@@ -46,6 +51,15 @@ func NewCSCollector() (Collector, error) {
 				"fqdn"},
 			nil,
 		),
+		Info: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "info"),
+			"Labeled identity of the computer, always 1 (ComputerSystemProduct.UUID, ComputerSystem.Domain and ComputerSystem.DomainRole)",
+			[]string{
+				"product_uuid",
+				"domain",
+				"domain_role"},
+			nil,
+		),
 	}, nil
 }
 
@@ -53,12 +67,55 @@ func NewCSCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *CSCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting cs metrics:", desc, err)
+		csLog.Error("failed collecting cs metrics:", desc, err)
 		return err
 	}
 	return nil
 }
 
+// Win32_ComputerSystemProduct docs:
+// - https://docs.microsoft.com/en-us/windows/win32/cimwin32prov/win32-computersystemproduct
+type Win32_ComputerSystemProduct struct {
+	UUID string
+}
+
+// Win32_ComputerSystem docs:
+// - https://docs.microsoft.com/en-us/windows/win32/cimwin32prov/win32-computersystem
+type Win32_ComputerSystem struct {
+	Name       string
+	Domain     string
+	DomainRole uint16
+}
+
+var csDomainRoles = map[uint16]string{
+	0: "standalone_workstation",
+	1: "member_workstation",
+	2: "standalone_server",
+	3: "member_server",
+	4: "backup_domain_controller",
+	5: "primary_domain_controller",
+}
+
+// csDomainRole maps the DomainRole property to the domain_role label.
+func csDomainRole(role uint16) string {
+	if r, ok := csDomainRoles[role]; ok {
+		return r
+	}
+	return "unknown"
+}
+
+// csProductUUID returns the lowercased product UUID, or an empty string for
+// the placeholders some firmwares and virtual machines report when they have
+// no UUID set.
+func csProductUUID(uuid string) string {
+	uuid = strings.ToLower(strings.TrimSpace(uuid))
+	switch uuid {
+	case "00000000-0000-0000-0000-000000000000", "ffffffff-ffff-ffff-ffff-ffffffffffff":
+		return ""
+	}
+	return uuid
+}
+
 func (c *CSCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	// Get systeminfo for number of processors
 	systemInfo := sysinfoapi.GetSystemInfo()
@@ -103,5 +160,35 @@ func (c *CSCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, er
 		fqdn,
 	)
 
+	var products []Win32_ComputerSystemProduct
+	if err := wmiQuery(queryAll(&products), &products); err != nil {
+		return c.Info, err
+	}
+	var systems []Win32_ComputerSystem
+	if err := wmiQuery(queryAll(&systems), &systems); err != nil {
+		return c.Info, err
+	}
+
+	var uuid string
+	if len(products) > 0 {
+		uuid = csProductUUID(products[0].UUID)
+	}
+	if uuid == "" {
+		csLog.Debug("No product UUID set for the computer, windows_cs_info has an empty product_uuid")
+	}
+	var system Win32_ComputerSystem
+	if len(systems) > 0 {
+		system = systems[0]
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Info,
+		prometheus.GaugeValue,
+		1.0,
+		uuid,
+		system.Domain,
+		csDomainRole(system.DomainRole),
+	)
+
 	return nil, nil
 }
@@ -7,3 +7,29 @@ import (
 func BenchmarkCsCollector(b *testing.B) {
 	benchmarkCollector(b, "cs", NewCSCollector)
 }
+
+func TestCSProductUUID(t *testing.T) {
+	for uuid, expected := range map[string]string{
+		"4C4C4544-0042-3510-8052-B4C04F564433": "4c4c4544-0042-3510-8052-b4c04f564433",
+		"00000000-0000-0000-0000-000000000000": "",
+		"FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF": "",
+		"":                                     "",
+	} {
+		if actual := csProductUUID(uuid); actual != expected {
+			t.Errorf("csProductUUID(%q): expected %q, got %q", uuid, expected, actual)
+		}
+	}
+}
+
+func TestCSDomainRole(t *testing.T) {
+	for role, expected := range map[uint16]string{
+		0: "standalone_workstation",
+		3: "member_server",
+		5: "primary_domain_controller",
+		6: "unknown",
+	} {
+		if actual := csDomainRole(role); actual != expected {
+			t.Errorf("csDomainRole(%d): expected %q, got %q", role, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("defender", NewDefenderCollector)
+}
+
+var defenderLog = log.With("collector", "defender")
+
+const defenderNamespace = `root\Microsoft\Windows\Defender`
+
+// A DefenderCollector is a Prometheus collector for WMI MSFT_MpComputerStatus metrics
+type DefenderCollector struct {
+	RealtimeProtectionEnabled *prometheus.Desc
+	SignatureAge              *prometheus.Desc
+	QuickScanAge              *prometheus.Desc
+}
+
+// NewDefenderCollector ...
+func NewDefenderCollector() (Collector, error) {
+	const subsystem = "defender"
+
+	return &DefenderCollector{
+		RealtimeProtectionEnabled: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "realtime_protection_enabled"),
+			"Whether real-time protection is enabled (RealTimeProtectionEnabled)",
+			nil,
+			nil,
+		),
+		SignatureAge: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "signature_age_days"),
+			"Age of the antivirus signatures, in days (AntivirusSignatureAge)",
+			nil,
+			nil,
+		),
+		QuickScanAge: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "quick_scan_age_days"),
+			"Number of days since the last quick scan (QuickScanAge)",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *DefenderCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		defenderLog.Error("failed collecting defender metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// MSFT_MpComputerStatus docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/defender/msft-mpcomputerstatus
+type MSFT_MpComputerStatus struct {
+	RealTimeProtectionEnabled bool
+	AntivirusSignatureAge     uint32
+	QuickScanAge              uint32
+}
+
+func (c *DefenderCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []MSFT_MpComputerStatus
+	q := queryAll(&dst)
+	if err := wmiQueryNamespace(q, &dst, defenderNamespace); err != nil {
+		// Defender is disabled or replaced by a third-party antivirus.
+		if isWMINotFoundError(err) {
+			defenderLog.Debugf("Windows Defender namespace not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(dst) == 0 {
+		return nil, nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.RealtimeProtectionEnabled,
+		prometheus.GaugeValue,
+		boolToFloat(dst[0].RealTimeProtectionEnabled),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.SignatureAge,
+		prometheus.GaugeValue,
+		float64(dst[0].AntivirusSignatureAge),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.QuickScanAge,
+		prometheus.GaugeValue,
+		float64(dst[0].QuickScanAge),
+	)
+
+	return nil, nil
+}
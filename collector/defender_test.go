@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkDefenderCollector(b *testing.B) {
+	benchmarkCollector(b, "defender", NewDefenderCollector)
+}
@@ -20,6 +20,8 @@ func init() {
 	registerCollector("dfsr", NewDFSRCollector, perflibDependencies...)
 }
 
+var dfsrLog = log.With("collector", "dfsr")
+
 // DFSRCollector contains the metric and state data of the DFSR collectors.
 type DFSRCollector struct {
 	// Connection source
@@ -93,7 +95,7 @@ func dfsrGetPerfObjectName(collector string) string {
 
 // NewDFSRCollector is registered
 func NewDFSRCollector() (Collector, error) {
-	log.Info("dfsr collector is in an experimental state! Metrics for this collector have not been tested.")
+	dfsrLog.Info("dfsr collector is in an experimental state! Metrics for this collector have not been tested.")
 	const subsystem = "dfsr"
 
 	enabled := expandEnabledChildCollectors(*dfsrEnabledCollectors)
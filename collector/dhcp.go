@@ -3,6 +3,8 @@
 package collector
 
 import (
+	"github.com/prometheus-community/windows_exporter/headers/dhcpsapi"
+	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -10,6 +12,8 @@ func init() {
 	registerCollector("dhcp", NewDhcpCollector, "DHCP Server")
 }
 
+var dhcpLog = log.With("collector", "dhcp")
+
 // A DhcpCollector is a Prometheus collector perflib DHCP metrics
 type DhcpCollector struct {
 	PacketsReceivedTotal                             *prometheus.Desc
@@ -37,6 +41,10 @@ type DhcpCollector struct {
 	FailoverTransitionsPartnerdownState              *prometheus.Desc
 	FailoverTransitionsRecoverState                  *prometheus.Desc
 	FailoverBndupdDropped                            *prometheus.Desc
+
+	ScopeAddressesFree  *prometheus.Desc
+	ScopeAddressesInUse *prometheus.Desc
+	ScopePendingOffers  *prometheus.Desc
 }
 
 func NewDhcpCollector() (Collector, error) {
@@ -193,6 +201,24 @@ func NewDhcpCollector() (Collector, error) {
 			nil,
 			nil,
 		),
+		ScopeAddressesFree: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "scope_addresses_free"),
+			"Number of free addresses in the IPv4 scope (NumAddressesFree)",
+			[]string{"scope"},
+			nil,
+		),
+		ScopeAddressesInUse: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "scope_addresses_in_use"),
+			"Number of addresses leased in the IPv4 scope (NumAddressesInuse)",
+			[]string{"scope"},
+			nil,
+		),
+		ScopePendingOffers: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "scope_pending_offers"),
+			"Number of addresses offered in the IPv4 scope, not yet requested (NumPendingOffers)",
+			[]string{"scope"},
+			nil,
+		),
 	}, nil
 }
 
@@ -228,6 +254,12 @@ type dhcpPerf struct {
 }
 
 func (c *DhcpCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	// The counters only exist when the DHCP Server role is installed
+	if ctx.perfObjects["DHCP Server"] == nil {
+		dhcpLog.Debug("DHCP Server performance counters not found. Skipping")
+		return nil
+	}
+
 	var perflib []dhcpPerf
 	if err := unmarshalObject(ctx.perfObjects["DHCP Server"], &perflib); err != nil {
 		return err
@@ -383,5 +415,40 @@ func (c *DhcpCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric)
 		perflib[0].FailoverBndupdDropped,
 	)
 
+	if desc, err := c.collectScopes(ch); err != nil {
+		dhcpLog.Error("failed collecting dhcp scope metrics:", desc, err)
+		return err
+	}
 	return nil
 }
+
+func (c *DhcpCollector) collectScopes(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	scopes, err := dhcpsapi.GetScopeStatistics()
+	if err != nil {
+		return c.ScopeAddressesFree, err
+	}
+
+	for _, scope := range scopes {
+		subnet := scope.Subnet.String()
+		ch <- prometheus.MustNewConstMetric(
+			c.ScopeAddressesFree,
+			prometheus.GaugeValue,
+			float64(scope.AddressesFree),
+			subnet,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.ScopeAddressesInUse,
+			prometheus.GaugeValue,
+			float64(scope.AddressesInUse),
+			subnet,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.ScopePendingOffers,
+			prometheus.GaugeValue,
+			float64(scope.PendingOffers),
+			subnet,
+		)
+	}
+
+	return nil, nil
+}
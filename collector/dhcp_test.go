@@ -2,8 +2,16 @@ package collector
 
 import (
 	"testing"
+
+	"github.com/prometheus-community/windows_exporter/headers/dhcpsapi"
 )
 
 func BenchmarkDHCPCollector(b *testing.B) {
 	benchmarkCollector(b, "dhcp", NewDhcpCollector)
 }
+
+func TestDHCPIPFromAddress(t *testing.T) {
+	if got := dhcpsapi.IPFromAddress(0xC0A80100).String(); got != "192.168.1.0" {
+		t.Errorf("expected 192.168.1.0, got %s", got)
+	}
+}
@@ -5,7 +5,6 @@ package collector
 import (
 	"errors"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -14,6 +13,8 @@ func init() {
 	registerCollector("dns", NewDNSCollector)
 }
 
+var dnsLog = log.With("collector", "dns")
+
 // A DNSCollector is a Prometheus collector for WMI Win32_PerfRawData_DNS_DNS metrics
 type DNSCollector struct {
 	ZoneTransferRequestsReceived  *prometheus.Desc
@@ -183,7 +184,7 @@ func NewDNSCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *DNSCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting dns metrics:", desc, err)
+		dnsLog.Error("failed collecting dns metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -238,7 +239,7 @@ type Win32_PerfRawData_DNS_DNS struct {
 func (c *DNSCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_DNS_DNS
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 	if len(dst) == 0 {
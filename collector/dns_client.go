@@ -0,0 +1,94 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("dns_client", newDNSClientCollector, "DNS Client")
+}
+
+var dnsClientLog = log.With("collector", "dns_client")
+
+// A DNSClientCollector is a Prometheus collector for Perflib DNS Client metrics
+type DNSClientCollector struct {
+	QueriesTotal *prometheus.Desc
+	CacheEntries *prometheus.Desc
+}
+
+func newDNSClientCollector() (Collector, error) {
+	const subsystem = "dns_client"
+
+	return &DNSClientCollector{
+		QueriesTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "queries_total"),
+			"Number of DNS queries sent by the resolver, by result",
+			[]string{"result"},
+			nil,
+		),
+		CacheEntries: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "cache_entries"),
+			"Number of entries in the resolver cache (DNS Client.Cache Entries)",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *DNSClientCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		dnsClientLog.Error("failed collecting dns_client metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib "DNS Client"
+type dnsClient struct {
+	QueriesSuccessful float64 `perflib:"Queries Successful"`
+	QueriesFailed     float64 `perflib:"Queries Failed"`
+	CacheEntries      float64 `perflib:"Cache Entries"`
+}
+
+func (c *DNSClientCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	obj, ok := ctx.perfObjects["DNS Client"]
+	if !ok {
+		dnsClientLog.Debug("DNS Client counters are not available. Skipping")
+		return nil, nil
+	}
+
+	var dst []dnsClient
+	if err := unmarshalObject(obj, &dst); err != nil {
+		return nil, err
+	}
+	if len(dst) == 0 {
+		return nil, nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.QueriesTotal,
+		prometheus.CounterValue,
+		dst[0].QueriesSuccessful,
+		"success",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.QueriesTotal,
+		prometheus.CounterValue,
+		dst[0].QueriesFailed,
+		"failure",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.CacheEntries,
+		prometheus.GaugeValue,
+		dst[0].CacheEntries,
+	)
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkDNSClientCollector(b *testing.B) {
+	benchmarkCollector(b, "dns_client", newDNSClientCollector)
+}
@@ -0,0 +1,88 @@
+// +build windows
+
+package collector
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// New returns a prometheus.Collector running the named collectors, for
+// programs embedding windows_exporter rather than running it standalone.
+//
+// The settings of the collectors are given as command line flags, e.g.
+// "--collector.service.services-where=Name='wuauserv'", which are parsed with
+// kingpin.CommandLine. Settings not given take their default value, so
+// programs parsing kingpin.CommandLine themselves should pass them all again.
+func New(enabled []string, flags ...string) (prometheus.Collector, error) {
+	if _, err := kingpin.CommandLine.Parse(flags); err != nil {
+		return nil, fmt.Errorf("invalid collector flags: %v", err)
+	}
+	if err := RegistrationError(); err != nil {
+		return nil, err
+	}
+
+	collectors := make(map[string]Collector, len(enabled))
+	for _, name := range enabled {
+		c, err := Build(name)
+		if err != nil {
+			return nil, err
+		}
+		collectors[name] = c
+	}
+	if err := CheckDescriptors(collectors); err != nil {
+		return nil, err
+	}
+
+	return &collectorSet{
+		collectors: collectors,
+		success: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "exporter", "collector_success"),
+			"windows_exporter: Whether the collector was successful.",
+			[]string{"collector"},
+			nil,
+		),
+	}, nil
+}
+
+// collectorSet runs a set of collectors in parallel on each collection. Unlike
+// the exporter, it doesn't time out, leaving it to the embedding program.
+type collectorSet struct {
+	collectors map[string]Collector
+	success    *prometheus.Desc
+}
+
+// Describe sends no descriptors, making collectorSet an unchecked collector:
+// the metrics of most collectors depend on the host.
+func (s *collectorSet) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect runs all collectors, and reports whether each succeeded.
+func (s *collectorSet) Collect(ch chan<- prometheus.Metric) {
+	names := make([]string, 0, len(s.collectors))
+	for name := range s.collectors {
+		names = append(names, name)
+	}
+	ctx, err := PrepareScrapeContext(names)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(s.success, fmt.Errorf("failed to prepare scrape: %v", err))
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(s.collectors))
+	for name, c := range s.collectors {
+		go func(name string, c Collector) {
+			defer wg.Done()
+			ch <- prometheus.MustNewConstMetric(
+				s.success,
+				prometheus.GaugeValue,
+				boolToFloat(c.Collect(ctx, ch) == nil),
+				name,
+			)
+		}(name, c)
+	}
+	wg.Wait()
+}
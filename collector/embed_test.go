@@ -0,0 +1,17 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestNewUnknownCollector(t *testing.T) {
+	if _, err := New([]string{"no_such_collector"}); err == nil {
+		t.Error("expected an error for an unknown collector")
+	}
+}
+
+func TestNewInvalidFlags(t *testing.T) {
+	if _, err := New(nil, "--collector.no-such-flag"); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+}
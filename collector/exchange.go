@@ -26,6 +26,8 @@ func init() {
 	)
 }
 
+var exchangeLog = log.With("collector", "exchange")
+
 type exchangeCollector struct {
 	LDAPReadTime                            *prometheus.Desc
 	LDAPSearchTime                          *prometheus.Desc
@@ -202,7 +204,7 @@ func (c *exchangeCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Met
 
 	for _, collectorName := range c.enabledCollectors {
 		if err := collectorFuncs[collectorName](ctx, ch); err != nil {
-			log.Errorf("Error in %s: %s", collectorName, err)
+			exchangeLog.Errorf("Error in %s: %s", collectorName, err)
 			return err
 		}
 	}
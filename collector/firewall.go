@@ -0,0 +1,207 @@
+// +build windows
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func init() {
+	registerCollector("firewall", NewFirewallCollector)
+}
+
+var firewallLog = log.With("collector", "firewall")
+
+const firewallNamespace = `root\StandardCimv2`
+
+var (
+	firewallProfiles = []string{"domain", "private", "public"}
+	// Values of the DefaultInboundAction property
+	firewallActions = map[uint16]string{
+		0: "not_configured",
+		2: "allow",
+		4: "block",
+	}
+	// Values of the Direction property
+	firewallDirections = map[uint16]string{
+		1: "inbound",
+		2: "outbound",
+	}
+)
+
+// A FirewallCollector is a Prometheus collector for WMI MSFT_NetFirewallProfile
+// and MSFT_NetFirewallRule metrics
+type FirewallCollector struct {
+	Enabled              *prometheus.Desc
+	DefaultInboundAction *prometheus.Desc
+	Rules                *prometheus.Desc
+}
+
+// NewFirewallCollector ...
+func NewFirewallCollector() (Collector, error) {
+	const subsystem = "firewall"
+
+	return &FirewallCollector{
+		Enabled: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "enabled"),
+			"Whether the firewall is enabled for the profile (Enabled)",
+			[]string{"profile"},
+			nil,
+		),
+		DefaultInboundAction: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "default_inbound_action"),
+			"The action applied to inbound connections matching no rule of the profile, 1 for the current action (DefaultInboundAction)",
+			[]string{"profile", "action"},
+			nil,
+		),
+		Rules: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "rules"),
+			"Number of enabled firewall rules applying to the profile",
+			[]string{"profile", "direction"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *FirewallCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		firewallLog.Error("failed collecting firewall metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// MSFT_NetFirewallProfile docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/wfascimprov/msft-netfirewallprofile
+type MSFT_NetFirewallProfile struct {
+	Name                 string
+	Enabled              uint16
+	DefaultInboundAction uint16
+}
+
+// MSFT_NetFirewallRule docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/wfascimprov/msft-netfirewallrule
+type MSFT_NetFirewallRule struct {
+	Direction uint16
+	Profiles  uint16
+}
+
+func (c *FirewallCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	running, err := firewallServiceRunning()
+	if err != nil {
+		return nil, err
+	}
+	if !running {
+		firewallLog.Debug("Windows Firewall service is not running. Skipping")
+		return nil, nil
+	}
+
+	var profiles []MSFT_NetFirewallProfile
+	q := queryAll(&profiles)
+	if err := wmiQueryNamespace(q, &profiles, firewallNamespace); err != nil {
+		if isWMINotFoundError(err) {
+			firewallLog.Debugf("Firewall WMI classes not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		name := strings.ToLower(profile.Name)
+		ch <- prometheus.MustNewConstMetric(
+			c.Enabled,
+			prometheus.GaugeValue,
+			boolToFloat(profile.Enabled == 1),
+			name,
+		)
+		for value, action := range firewallActions {
+			ch <- prometheus.MustNewConstMetric(
+				c.DefaultInboundAction,
+				prometheus.GaugeValue,
+				boolToFloat(profile.DefaultInboundAction == value),
+				name,
+				action,
+			)
+		}
+	}
+
+	var rules []MSFT_NetFirewallRule
+	q = queryAllWhere(&rules, "Enabled = 1")
+	if err := wmiQueryNamespace(q, &rules, firewallNamespace); err != nil {
+		return c.Rules, err
+	}
+
+	counts := make(map[[2]string]int)
+	for _, profile := range firewallProfiles {
+		for _, direction := range firewallDirections {
+			counts[[2]string{profile, direction}] = 0
+		}
+	}
+	for _, rule := range rules {
+		direction, ok := firewallDirections[rule.Direction]
+		if !ok {
+			continue
+		}
+		for _, profile := range firewallRuleProfiles(rule.Profiles) {
+			counts[[2]string{profile, direction}]++
+		}
+	}
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.Rules,
+			prometheus.GaugeValue,
+			float64(count),
+			key[0],
+			key[1],
+		)
+	}
+
+	return nil, nil
+}
+
+// firewallRuleProfiles returns the profiles a rule applies to, from the
+// bitmask of its Profiles property. A rule with no profile applies to all.
+func firewallRuleProfiles(mask uint16) []string {
+	if mask == 0 {
+		return firewallProfiles
+	}
+	var profiles []string
+	for i, profile := range firewallProfiles {
+		if mask&(1<<uint(i)) != 0 {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// firewallServiceRunning reports whether the Windows Firewall service (MpsSvc)
+// is running. Its configuration is readable while stopped, but not enforced.
+func firewallServiceRunning() (bool, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return false, err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService("MpsSvc")
+	if err != nil {
+		if err == windows.ERROR_SERVICE_DOES_NOT_EXIST {
+			return false, nil
+		}
+		return false, err
+	}
+	defer s.Close()
+
+	status, err := queryServiceStatus(s.Handle)
+	if err != nil {
+		return false, err
+	}
+	return status.CurrentState == windows.SERVICE_RUNNING, nil
+}
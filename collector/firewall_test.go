@@ -0,0 +1,27 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func BenchmarkFirewallCollector(b *testing.B) {
+	benchmarkCollector(b, "firewall", NewFirewallCollector)
+}
+
+func TestFirewallRuleProfiles(t *testing.T) {
+	cases := []struct {
+		mask uint16
+		want []string
+	}{
+		{0, []string{"domain", "private", "public"}},
+		{1, []string{"domain"}},
+		{6, []string{"private", "public"}},
+		{7, []string{"domain", "private", "public"}},
+	}
+	for _, c := range cases {
+		if got := firewallRuleProfiles(c.mask); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("firewallRuleProfiles(%d) = %v, want %v", c.mask, got, c.want)
+		}
+	}
+}
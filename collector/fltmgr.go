@@ -0,0 +1,107 @@
+// +build windows
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("fltmgr", newFltMgrCollector, "Filter Manager Instance")
+}
+
+var fltmgrLog = log.With("collector", "fltmgr")
+
+// A FltMgrCollector is a Prometheus collector for Perflib Filter Manager Instance metrics
+type FltMgrCollector struct {
+	InstanceLatency    *prometheus.Desc
+	InstanceOperations *prometheus.Desc
+}
+
+func newFltMgrCollector() (Collector, error) {
+	const subsystem = "fltmgr"
+
+	return &FltMgrCollector{
+		InstanceLatency: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "instance_latency_seconds_total"),
+			"Total time spent by the minifilter instance processing I/O operations (Filter Manager Instance.Average Latency)",
+			[]string{"filter", "volume"},
+			nil,
+		),
+		InstanceOperations: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "instance_operations_total"),
+			"Total number of I/O operations processed by the minifilter instance (Filter Manager Instance.Average Latency_Base)",
+			[]string{"filter", "volume"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *FltMgrCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		fltmgrLog.Error("failed collecting fltmgr metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib "Filter Manager Instance"
+type fltMgrInstance struct {
+	Name string
+
+	AverageLatency     float64 `perflib:"Average Latency"`
+	AverageLatencyBase float64 `perflib:"Average Latency_Base"`
+}
+
+func (c *FltMgrCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	obj, ok := ctx.perfObjects["Filter Manager Instance"]
+	if !ok {
+		// The counter set is only registered on Windows versions where the
+		// Filter Manager publishes per-instance statistics.
+		fltmgrLog.Debug("Filter Manager Instance counters are not available. Skipping")
+		return nil, nil
+	}
+
+	var dst []fltMgrInstance
+	if err := unmarshalObject(obj, &dst); err != nil {
+		return nil, err
+	}
+
+	for _, instance := range dst {
+		if instance.Name == "_Total" {
+			continue
+		}
+		filter, volume := splitFltMgrInstanceName(instance.Name)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.InstanceLatency,
+			prometheus.CounterValue,
+			instance.AverageLatency*ticksToSecondsScaleFactor,
+			filter, volume,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.InstanceOperations,
+			prometheus.CounterValue,
+			instance.AverageLatencyBase,
+			filter, volume,
+		)
+	}
+
+	return nil, nil
+}
+
+// splitFltMgrInstanceName splits an instance name of the form
+// "<filter> <volume>" into its filter and volume parts.
+func splitFltMgrInstanceName(name string) (string, string) {
+	parts := strings.SplitN(name, " ", 2)
+	if len(parts) != 2 {
+		return name, ""
+	}
+	return parts[0], parts[1]
+}
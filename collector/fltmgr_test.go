@@ -0,0 +1,27 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestSplitFltMgrInstanceName(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter string
+		volume string
+	}{
+		{"WdFilter C:", "WdFilter", "C:"},
+		{`bindflt \Device\HarddiskVolume3`, "bindflt", `\Device\HarddiskVolume3`},
+		{"FileInfo", "FileInfo", ""},
+	}
+	for _, c := range cases {
+		filter, volume := splitFltMgrInstanceName(c.name)
+		if filter != c.filter || volume != c.volume {
+			t.Errorf("%q: expected (%q, %q), got (%q, %q)", c.name, c.filter, c.volume, filter, volume)
+		}
+	}
+}
+
+func BenchmarkFltMgrCollector(b *testing.B) {
+	benchmarkCollector(b, "fltmgr", newFltMgrCollector)
+}
@@ -1,7 +1,6 @@
 package collector
 
 import (
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -10,6 +9,8 @@ func init() {
 	registerCollector("fsrmquota", newFSRMQuotaCollector)
 }
 
+var fsrmquotaLog = log.With("collector", "fsrmquota")
+
 type FSRMQuotaCollector struct {
 	QuotasCount *prometheus.Desc
 	Path        *prometheus.Desc
@@ -88,7 +89,7 @@ func newFSRMQuotaCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *FSRMQuotaCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting fsrmquota metrics:", desc, err)
+		fsrmquotaLog.Error("failed collecting fsrmquota metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -117,7 +118,7 @@ func (c *FSRMQuotaCollector) collect(ch chan<- prometheus.Metric) (*prometheus.D
 
 	var count int
 
-	if err := wmi.QueryNamespace(q, &dst, "root/microsoft/windows/fsrm"); err != nil {
+	if err := wmiQueryNamespace(q, &dst, "root/microsoft/windows/fsrm"); err != nil {
 		return nil, err
 	}
 
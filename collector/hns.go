@@ -0,0 +1,163 @@
+// +build windows
+
+package collector
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Microsoft/hcsshim"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("hns", NewHNSCollector)
+}
+
+var hnsLog = log.With("collector", "hns")
+
+// A HNSCollector is a Prometheus collector for Host Networking Service (HNS) metrics
+type HNSCollector struct {
+	ACLPolicyCount *prometheus.Desc
+	Endpoints      *prometheus.Desc
+	EndpointState  *prometheus.Desc
+	Networks       *prometheus.Desc
+}
+
+// NewHNSCollector ...
+func NewHNSCollector() (Collector, error) {
+	const subsystem = "hns"
+
+	return &HNSCollector{
+		ACLPolicyCount: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "acl_policy_count"),
+			"Number of ACL policies (network policies) applied to the endpoint",
+			[]string{"endpoint"},
+			nil,
+		),
+		Endpoints: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "endpoints"),
+			"Number of HNS endpoints",
+			nil,
+			nil,
+		),
+		EndpointState: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "endpoint_state"),
+			"The state of the endpoint, 1 if the current state, 0 otherwise",
+			[]string{"endpoint", "state"},
+			nil,
+		),
+		Networks: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "networks"),
+			"Number of HNS networks, by type",
+			[]string{"type"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *HNSCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		hnsLog.Error("failed collecting hns metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// hnsPolicy holds the common part of all HNS policy documents.
+type hnsPolicy struct {
+	Type hcsshim.PolicyType
+}
+
+func (c *HNSCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	endpoints, err := hcsshim.HNSListEndpointRequest()
+	if err != nil {
+		hnsLog.Debugf("Could not query HNS endpoints: %v. Skipping", err)
+		return nil, nil
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Endpoints,
+		prometheus.GaugeValue,
+		float64(len(endpoints)),
+	)
+	for _, endpoint := range endpoints {
+		ch <- prometheus.MustNewConstMetric(
+			c.ACLPolicyCount,
+			prometheus.GaugeValue,
+			float64(countHNSPolicies(endpoint.Policies, hcsshim.ACL)),
+			endpoint.Id,
+		)
+		state := hnsEndpointState(endpoint)
+		for _, s := range allHNSEndpointStates {
+			ch <- prometheus.MustNewConstMetric(
+				c.EndpointState,
+				prometheus.GaugeValue,
+				boolToFloat(s == state),
+				endpoint.Id,
+				s,
+			)
+		}
+	}
+
+	networks, err := hcsshim.HNSListNetworkRequest("GET", "", "")
+	if err != nil {
+		return c.Networks, err
+	}
+	for networkType, count := range countHNSNetworks(networks) {
+		ch <- prometheus.MustNewConstMetric(
+			c.Networks,
+			prometheus.GaugeValue,
+			count,
+			networkType,
+		)
+	}
+
+	return nil, nil
+}
+
+var allHNSEndpointStates = []string{"attached", "detached", "remote"}
+
+// hnsEndpointState returns the state of the endpoint: remote for endpoints of
+// other hosts of an overlay network, attached for local endpoints attached to
+// a network namespace, and detached otherwise. The HNS v1 API doesn't report
+// the attachment of endpoints to container compartments, as used by Docker.
+func hnsEndpointState(endpoint hcsshim.HNSEndpoint) string {
+	switch {
+	case endpoint.IsRemoteEndpoint:
+		return "remote"
+	case endpoint.Namespace != nil:
+		return "attached"
+	}
+	return "detached"
+}
+
+// countHNSNetworks returns the number of networks of each type, e.g. nat or
+// l2bridge. Types are lowercased, as HNS doesn't report them consistently.
+func countHNSNetworks(networks []hcsshim.HNSNetwork) map[string]float64 {
+	counts := make(map[string]float64)
+	for _, network := range networks {
+		counts[strings.ToLower(network.Type)]++
+	}
+	return counts
+}
+
+// countHNSPolicies returns the number of policies of the given type.
+// Policies which can't be decoded are ignored.
+func countHNSPolicies(policies []json.RawMessage, policyType hcsshim.PolicyType) int {
+	count := 0
+	for _, raw := range policies {
+		var policy hnsPolicy
+		if err := json.Unmarshal(raw, &policy); err != nil {
+			hnsLog.Debugf("Could not decode HNS policy: %v", err)
+			continue
+		}
+		if policy.Type == policyType {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Microsoft/hcsshim"
+)
+
+func TestCountHNSPolicies(t *testing.T) {
+	policies := []json.RawMessage{
+		json.RawMessage(`{"Type":"ACL","Action":"Allow","Direction":"In"}`),
+		json.RawMessage(`{"Type":"ACL","Action":"Block","Direction":"Out"}`),
+		json.RawMessage(`{"Type":"OutBoundNAT","ExceptionList":["10.0.0.0/8"]}`),
+		json.RawMessage(`not json`),
+	}
+	if got := countHNSPolicies(policies, hcsshim.ACL); got != 2 {
+		t.Errorf("expected 2 ACL policies, got %d", got)
+	}
+	if got := countHNSPolicies(nil, hcsshim.ACL); got != 0 {
+		t.Errorf("expected 0 ACL policies, got %d", got)
+	}
+}
+
+func TestHNSEndpointState(t *testing.T) {
+	cases := []struct {
+		endpoint hcsshim.HNSEndpoint
+		want     string
+	}{
+		{hcsshim.HNSEndpoint{}, "detached"},
+		{hcsshim.HNSEndpoint{Namespace: &hcsshim.Namespace{ID: "ns"}}, "attached"},
+		{hcsshim.HNSEndpoint{IsRemoteEndpoint: true}, "remote"},
+	}
+	for _, tc := range cases {
+		if got := hnsEndpointState(tc.endpoint); got != tc.want {
+			t.Errorf("expected %s, got %s", tc.want, got)
+		}
+	}
+}
+
+func TestCountHNSNetworks(t *testing.T) {
+	networks := []hcsshim.HNSNetwork{{Type: "NAT"}, {Type: "nat"}, {Type: "L2Bridge"}}
+	want := map[string]float64{"nat": 2, "l2bridge": 1}
+	if got := countHNSNetworks(networks); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func BenchmarkHNSCollector(b *testing.B) {
+	benchmarkCollector(b, "hns", NewHNSCollector)
+}
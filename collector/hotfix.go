@@ -0,0 +1,166 @@
+// +build windows
+
+package collector
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func init() {
+	registerCollector("hotfix", NewHotfixCollector)
+}
+
+var hotfixLog = log.With("collector", "hotfix")
+
+var (
+	hotfixMaxAge = kingpin.Flag(
+		"collector.hotfix.max-age",
+		"Exclude hotfixes installed longer ago than this duration. 0 to include all hotfixes.",
+	).Default("0s").Duration()
+)
+
+// A HotfixCollector is a Prometheus collector for WMI Win32_QuickFixEngineering metrics
+type HotfixCollector struct {
+	Installed          *prometheus.Desc
+	InstalledTimestamp *prometheus.Desc
+
+	maxAge time.Duration
+}
+
+// NewHotfixCollector ...
+func NewHotfixCollector() (Collector, error) {
+	const subsystem = "hotfix"
+
+	return &HotfixCollector{
+		Installed: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "installed"),
+			"A metric with a constant '1' value for each installed hotfix",
+			[]string{"hotfix_id", "installed_on"},
+			nil,
+		),
+		InstalledTimestamp: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "installed_timestamp_seconds"),
+			"Date the hotfix was installed, as a unix timestamp. Only exposed for hotfixes with a known install date",
+			[]string{"hotfix_id"},
+			nil,
+		),
+		maxAge: *hotfixMaxAge,
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *HotfixCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		hotfixLog.Error("failed collecting hotfix metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Win32_QuickFixEngineering docs:
+// - https://docs.microsoft.com/en-us/windows/win32/cimwin32prov/win32-quickfixengineering
+type Win32_QuickFixEngineering struct {
+	HotFixID    string
+	InstalledOn string
+}
+
+func (c *HotfixCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []Win32_QuickFixEngineering
+	q := queryAll(&dst)
+	if err := wmiQuery(q, &dst); err != nil {
+		return nil, err
+	}
+
+	installed, timestamps := dedupeHotfixes(dst, time.Now(), c.maxAge)
+	for _, hotfix := range installed {
+		ch <- prometheus.MustNewConstMetric(
+			c.Installed,
+			prometheus.GaugeValue,
+			1.0,
+			hotfix.id,
+			hotfix.installedOn,
+		)
+	}
+	for id, date := range timestamps {
+		ch <- prometheus.MustNewConstMetric(
+			c.InstalledTimestamp,
+			prometheus.GaugeValue,
+			float64(date.Unix()),
+			id,
+		)
+	}
+
+	return nil, nil
+}
+
+type hotfixInstalled struct {
+	id          string
+	installedOn string
+}
+
+// dedupeHotfixes returns the label values of the installed metric, and the
+// install date of each hotfix with a known one, leaving out the hotfixes
+// older than maxAge. Win32_QuickFixEngineering lists some hotfixes several
+// times, e.g. a KB installed twice or several "File 1" entries, which would
+// otherwise give duplicate series. When the dates of a hotfix differ, its
+// timestamp is the latest one.
+func dedupeHotfixes(hotfixes []Win32_QuickFixEngineering, now time.Time, maxAge time.Duration) ([]hotfixInstalled, map[string]time.Time) {
+	var installed []hotfixInstalled
+	seen := make(map[hotfixInstalled]bool, len(hotfixes))
+	timestamps := make(map[string]time.Time)
+	for _, hotfix := range hotfixes {
+		installedOn := strings.TrimSpace(hotfix.InstalledOn)
+		date, ok := parseHotfixInstalledOn(installedOn)
+		if ok {
+			if maxAge > 0 && now.Sub(date) > maxAge {
+				continue
+			}
+			installedOn = date.Format("2006-01-02")
+			if latest, found := timestamps[hotfix.HotFixID]; !found || date.After(latest) {
+				timestamps[hotfix.HotFixID] = date
+			}
+		}
+
+		entry := hotfixInstalled{id: hotfix.HotFixID, installedOn: installedOn}
+		if !seen[entry] {
+			seen[entry] = true
+			installed = append(installed, entry)
+		}
+	}
+	return installed, timestamps
+}
+
+// Layouts of the InstalledOn property, which isn't a proper WMI datetime and
+// depends on the locale and the installer of the hotfix.
+var hotfixInstalledOnLayouts = []string{
+	"1/2/2006",
+	"2006-01-02",
+	"20060102",
+}
+
+// parseHotfixInstalledOn parses the InstalledOn property of a hotfix, which
+// can be a date string, a hexadecimal FILETIME, or empty.
+func parseHotfixInstalledOn(installedOn string) (time.Time, bool) {
+	if installedOn == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range hotfixInstalledOnLayouts {
+		if t, err := time.Parse(layout, installedOn); err == nil {
+			return t, true
+		}
+	}
+	// Some hotfixes report a FILETIME, as 16 hexadecimal digits.
+	if len(installedOn) == 16 {
+		if ft, err := strconv.ParseUint(installedOn, 16, 64); err == nil && ft > windowsEpoch {
+			return time.Unix(0, int64(ft-windowsEpoch)*100).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
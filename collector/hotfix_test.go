@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHotfixInstalledOn(t *testing.T) {
+	cases := []struct {
+		installedOn string
+		expected    time.Time
+		ok          bool
+	}{
+		{"3/9/2021", time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC), true},
+		{"11/10/2020", time.Date(2020, 11, 10, 0, 0, 0, 0, time.UTC), true},
+		{"2021-03-09", time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC), true},
+		{"20210309", time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC), true},
+		{"01d7147725e2c000", time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC), true},
+		{"", time.Time{}, false},
+		{"31/12/2020", time.Time{}, false},
+	}
+	for _, c := range cases {
+		got, ok := parseHotfixInstalledOn(c.installedOn)
+		if ok != c.ok || !got.Equal(c.expected) {
+			t.Errorf("%q: expected (%v, %v), got (%v, %v)", c.installedOn, c.expected, c.ok, got, ok)
+		}
+	}
+}
+
+func TestDedupeHotfixes(t *testing.T) {
+	hotfixes := []Win32_QuickFixEngineering{
+		{HotFixID: "KB5000802", InstalledOn: "3/9/2021"},
+		{HotFixID: "KB5000802", InstalledOn: "3/9/2021"},
+		{HotFixID: "KB4601050", InstalledOn: "2/10/2021"},
+		{HotFixID: "KB4601050", InstalledOn: "3/1/2021"},
+		{HotFixID: "File 1", InstalledOn: ""},
+		{HotFixID: "File 1", InstalledOn: ""},
+		{HotFixID: "KB4023057", InstalledOn: "1/5/2019"},
+	}
+	now := time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	installed, timestamps := dedupeHotfixes(hotfixes, now, 365*24*time.Hour)
+	expectedInstalled := []hotfixInstalled{
+		{"KB5000802", "2021-03-09"},
+		{"KB4601050", "2021-02-10"},
+		{"KB4601050", "2021-03-01"},
+		{"File 1", ""},
+	}
+	if !reflect.DeepEqual(installed, expectedInstalled) {
+		t.Errorf("expected installed %v, got %v", expectedInstalled, installed)
+	}
+	expectedTimestamps := map[string]time.Time{
+		"KB5000802": time.Date(2021, 3, 9, 0, 0, 0, 0, time.UTC),
+		"KB4601050": time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(timestamps, expectedTimestamps) {
+		t.Errorf("expected timestamps %v, got %v", expectedTimestamps, timestamps)
+	}
+}
+
+func BenchmarkHotfixCollector(b *testing.B) {
+	benchmarkCollector(b, "hotfix", NewHotfixCollector)
+}
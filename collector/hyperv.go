@@ -5,7 +5,6 @@ package collector
 import (
 	"strings"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -14,6 +13,8 @@ func init() {
 	registerCollector("hyperv", NewHyperVCollector)
 }
 
+var hypervLog = log.With("collector", "hyperv")
+
 // HyperVCollector is a Prometheus collector for hyper-v
 type HyperVCollector struct {
 	// Win32_PerfRawData_VmmsVirtualMachineStats_HyperVVirtualMachineHealthSummary
@@ -58,6 +59,9 @@ type HyperVCollector struct {
 	HostRemoteRunTime     *prometheus.Desc
 	HostTotalRunTime      *prometheus.Desc
 
+	// Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor
+	HostLogicalProcessorRunTime *prometheus.Desc
+
 	// Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor
 	VMGuestRunTime      *prometheus.Desc
 	VMHypervisorRunTime *prometheus.Desc
@@ -323,6 +327,15 @@ func NewHyperVCollector() (Collector, error) {
 
 		//
 
+		HostLogicalProcessorRunTime: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, buildSubsystemName("host"), "logical_processor_run_time"),
+			"The time spent by the logical processor by mode (guest, hypervisor, idle)",
+			[]string{"core", "mode"},
+			nil,
+		),
+
+		//
+
 		VMGuestRunTime: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, buildSubsystemName("vm_cpu"), "guest_run_time"),
 			"The time spent by the virtual processor in guest code",
@@ -599,52 +612,57 @@ func NewHyperVCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *HyperVCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collectVmHealth(ch); err != nil {
-		log.Error("failed collecting hyperV health status metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV health status metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmVid(ch); err != nil {
-		log.Error("failed collecting hyperV pages metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV pages metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmHv(ch); err != nil {
-		log.Error("failed collecting hyperV hv status metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV hv status metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmProcessor(ch); err != nil {
-		log.Error("failed collecting hyperV processor metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV processor metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectHostCpuUsage(ch); err != nil {
-		log.Error("failed collecting hyperV host CPU metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV host CPU metrics:", desc, err)
+		return err
+	}
+
+	if desc, err := c.collectHostLogicalProcessorUsage(ch); err != nil {
+		hypervLog.Error("failed collecting hyperV host logical processor metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmCpuUsage(ch); err != nil {
-		log.Error("failed collecting hyperV VM CPU metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV VM CPU metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmSwitch(ch); err != nil {
-		log.Error("failed collecting hyperV switch metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV switch metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmEthernet(ch); err != nil {
-		log.Error("failed collecting hyperV ethernet metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV ethernet metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmStorage(ch); err != nil {
-		log.Error("failed collecting hyperV virtual storage metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV virtual storage metrics:", desc, err)
 		return err
 	}
 
 	if desc, err := c.collectVmNetwork(ch); err != nil {
-		log.Error("failed collecting hyperV virtual network metrics:", desc, err)
+		hypervLog.Error("failed collecting hyperV virtual network metrics:", desc, err)
 		return err
 	}
 
@@ -660,7 +678,7 @@ type Win32_PerfRawData_VmmsVirtualMachineStats_HyperVVirtualMachineHealthSummary
 func (c *HyperVCollector) collectVmHealth(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_VmmsVirtualMachineStats_HyperVVirtualMachineHealthSummary
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -693,7 +711,7 @@ type Win32_PerfRawData_VidPerfProvider_HyperVVMVidPartition struct {
 func (c *HyperVCollector) collectVmVid(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_VidPerfProvider_HyperVVMVidPartition
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -757,7 +775,7 @@ type Win32_PerfRawData_HvStats_HyperVHypervisorRootPartition struct {
 func (c *HyperVCollector) collectVmHv(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorRootPartition
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -895,7 +913,7 @@ type Win32_PerfRawData_HvStats_HyperVHypervisor struct {
 func (c *HyperVCollector) collectVmProcessor(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisor
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -930,7 +948,7 @@ type Win32_PerfRawData_HvStats_HyperVHypervisorRootVirtualProcessor struct {
 func (c *HyperVCollector) collectHostCpuUsage(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorRootVirtualProcessor
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -941,7 +959,7 @@ func (c *HyperVCollector) collectHostCpuUsage(ch chan<- prometheus.Metric) (*pro
 		// The name format is Root VP <core id>
 		parts := strings.Split(obj.Name, " ")
 		if len(parts) != 3 {
-			log.Warnf("Unexpected format of Name in collectHostCpuUsage: %q", obj.Name)
+			hypervLog.Warnf("Unexpected format of Name in collectHostCpuUsage: %q", obj.Name)
 			continue
 		}
 		coreId := parts[2]
@@ -979,6 +997,58 @@ func (c *HyperVCollector) collectHostCpuUsage(ch chan<- prometheus.Metric) (*pro
 	return nil, nil
 }
 
+// Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor ...
+type Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor struct {
+	Name                     string
+	PercentGuestRunTime      uint64
+	PercentHypervisorRunTime uint64
+	PercentIdleTime          uint64
+}
+
+func (c *HyperVCollector) collectHostLogicalProcessorUsage(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorLogicalProcessor
+	q := queryAll(&dst)
+	if err := wmiQuery(q, &dst); err != nil {
+		return nil, err
+	}
+
+	for _, obj := range dst {
+		if strings.Contains(obj.Name, "_Total") {
+			continue
+		}
+		// The name format is Hv LP <core id>
+		parts := strings.Split(obj.Name, " ")
+		if len(parts) != 3 {
+			hypervLog.Warnf("Unexpected format of Name in collectHostLogicalProcessorUsage: %q", obj.Name)
+			continue
+		}
+		coreId := parts[2]
+
+		ch <- prometheus.MustNewConstMetric(
+			c.HostLogicalProcessorRunTime,
+			prometheus.GaugeValue,
+			float64(obj.PercentGuestRunTime),
+			coreId, "guest",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.HostLogicalProcessorRunTime,
+			prometheus.GaugeValue,
+			float64(obj.PercentHypervisorRunTime),
+			coreId, "hypervisor",
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.HostLogicalProcessorRunTime,
+			prometheus.GaugeValue,
+			float64(obj.PercentIdleTime),
+			coreId, "idle",
+		)
+	}
+
+	return nil, nil
+}
+
 // Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor ...
 type Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor struct {
 	Name                     string
@@ -991,7 +1061,7 @@ type Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor struct {
 func (c *HyperVCollector) collectVmCpuUsage(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_HvStats_HyperVHypervisorVirtualProcessor
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -1002,12 +1072,12 @@ func (c *HyperVCollector) collectVmCpuUsage(ch chan<- prometheus.Metric) (*prome
 		// The name format is <VM Name>:Hv VP <vcore id>
 		parts := strings.Split(obj.Name, ":")
 		if len(parts) != 2 {
-			log.Warnf("Unexpected format of Name in collectVmCpuUsage: %q, expected %q. Skipping.", obj.Name, "<VM Name>:Hv VP <vcore id>")
+			hypervLog.Warnf("Unexpected format of Name in collectVmCpuUsage: %q, expected %q. Skipping.", obj.Name, "<VM Name>:Hv VP <vcore id>")
 			continue
 		}
 		coreParts := strings.Split(parts[1], " ")
 		if len(coreParts) != 3 {
-			log.Warnf("Unexpected format of core identifier in collectVmCpuUsage: %q, expected %q. Skipping.", parts[1], "Hv VP <vcore id>")
+			hypervLog.Warnf("Unexpected format of core identifier in collectVmCpuUsage: %q, expected %q. Skipping.", parts[1], "Hv VP <vcore id>")
 			continue
 		}
 		vmName := parts[0]
@@ -1078,7 +1148,7 @@ type Win32_PerfRawData_NvspSwitchStats_HyperVVirtualSwitch struct {
 func (c *HyperVCollector) collectVmSwitch(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_NvspSwitchStats_HyperVVirtualSwitch
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -1237,7 +1307,7 @@ type Win32_PerfRawData_EthernetPerfProvider_HyperVLegacyNetworkAdapter struct {
 func (c *HyperVCollector) collectVmEthernet(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_EthernetPerfProvider_HyperVLegacyNetworkAdapter
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -1307,7 +1377,7 @@ type Win32_PerfRawData_Counters_HyperVVirtualStorageDevice struct {
 func (c *HyperVCollector) collectVmStorage(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_Counters_HyperVVirtualStorageDevice
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -1376,7 +1446,7 @@ type Win32_PerfRawData_NvspNicStats_HyperVVirtualNetworkAdapter struct {
 func (c *HyperVCollector) collectVmNetwork(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_NvspNicStats_HyperVVirtualNetworkAdapter
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
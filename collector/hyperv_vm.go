@@ -0,0 +1,273 @@
+// +build windows
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("hyperv_vm", NewHyperVVMCollector)
+}
+
+var hypervVmLog = log.With("collector", "hyperv_vm")
+
+const hypervVirtualizationNamespace = `root\virtualization\v2`
+
+// A HyperVVMCollector is a Prometheus collector for WMI Msvm_ComputerSystem, Msvm_SummaryInformation and Msvm_ProcessorSettingData metrics
+type HyperVVMCollector struct {
+	Info           *prometheus.Desc
+	State          *prometheus.Desc
+	CPUUsage       *prometheus.Desc
+	MemoryAssigned *prometheus.Desc
+
+	VirtualProcessors *prometheus.Desc
+	CPUReservation    *prometheus.Desc
+	CPULimit          *prometheus.Desc
+	CPUWeight         *prometheus.Desc
+}
+
+// NewHyperVVMCollector ...
+func NewHyperVVMCollector() (Collector, error) {
+	const subsystem = "hyperv_vm"
+
+	return &HyperVVMCollector{
+		Info: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "info"),
+			"A metric with a constant '1' value labeled with the ID and name of the virtual machine",
+			[]string{"vm", "name"},
+			nil,
+		),
+		State: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "state"),
+			"The state of the virtual machine (EnabledState)",
+			[]string{"vm", "state"},
+			nil,
+		),
+		CPUUsage: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "cpu_usage_percent"),
+			"Average load of the virtual processors of the virtual machine (ProcessorLoad)",
+			[]string{"vm"},
+			nil,
+		),
+		MemoryAssigned: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "memory_assigned_bytes"),
+			"Memory currently assigned to the virtual machine (MemoryUsage)",
+			[]string{"vm"},
+			nil,
+		),
+		VirtualProcessors: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "virtual_processors"),
+			"Number of virtual processors configured for the virtual machine (VirtualQuantity)",
+			[]string{"vm"},
+			nil,
+		),
+		CPUReservation: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "cpu_reservation_percent"),
+			"Percentage of the virtual processors' capacity reserved for the virtual machine (Reservation)",
+			[]string{"vm"},
+			nil,
+		),
+		CPULimit: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "cpu_limit_percent"),
+			"Maximum percentage of the virtual processors' capacity the virtual machine may use (Limit)",
+			[]string{"vm"},
+			nil,
+		),
+		CPUWeight: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "cpu_weight"),
+			"Relative weight of the virtual machine when competing for processor resources (Weight)",
+			[]string{"vm"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *HyperVVMCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		hypervVmLog.Error("failed collecting hyperv_vm metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Msvm_ComputerSystem docs:
+// - https://docs.microsoft.com/en-us/windows/win32/hyperv_v2/msvm-computersystem
+type Msvm_ComputerSystem struct {
+	Name         string
+	ElementName  string
+	EnabledState uint16
+}
+
+// Msvm_SummaryInformation docs:
+// - https://docs.microsoft.com/en-us/windows/win32/hyperv_v2/msvm-summaryinformation
+type Msvm_SummaryInformation struct {
+	Name          string
+	ProcessorLoad uint16
+	MemoryUsage   uint64
+}
+
+// Msvm_ProcessorSettingData docs:
+// - https://docs.microsoft.com/en-us/windows/win32/hyperv_v2/msvm-processorsettingdata
+type Msvm_ProcessorSettingData struct {
+	InstanceID      string
+	VirtualQuantity uint64
+	Reservation     uint64
+	Limit           uint64
+	Weight          uint32
+}
+
+var (
+	allHyperVVMStates = []string{
+		"running",
+		"off",
+		"saved",
+		"paused",
+		"starting",
+		"stopping",
+		"saving",
+		"pausing",
+		"resuming",
+		"unknown",
+	}
+	hypervVMStateValues = map[uint16]string{
+		2:     "running",
+		3:     "off",
+		4:     "stopping",
+		6:     "saved",
+		9:     "paused",
+		10:    "starting",
+		32768: "paused",
+		32769: "saved",
+		32770: "starting",
+		32773: "saving",
+		32774: "stopping",
+		32776: "pausing",
+		32777: "resuming",
+	}
+)
+
+func (c *HyperVVMCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var systems []Msvm_ComputerSystem
+	// The host itself is also represented by a Msvm_ComputerSystem instance.
+	q := queryAllWhere(&systems, "Caption = 'Virtual Machine'")
+	if err := wmiQueryNamespace(q, &systems, hypervVirtualizationNamespace); err != nil {
+		if isWMINotFoundError(err) {
+			hypervVmLog.Debugf("Hyper-V virtualization namespace not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// The names of virtual machines aren't unique, so they are labeled with
+	// their ID, the Name property.
+	vmIDs := make(map[string]string, len(systems))
+	for _, vm := range systems {
+		vmIDs[strings.ToUpper(vm.Name)] = vm.Name
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Info,
+			prometheus.GaugeValue,
+			1.0,
+			vm.Name,
+			vm.ElementName,
+		)
+
+		currentState, ok := hypervVMStateValues[vm.EnabledState]
+		if !ok {
+			currentState = "unknown"
+		}
+		for _, state := range allHyperVVMStates {
+			isCurrentState := 0.0
+			if state == currentState {
+				isCurrentState = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.State,
+				prometheus.GaugeValue,
+				isCurrentState,
+				vm.Name,
+				state,
+			)
+		}
+	}
+
+	var summaries []Msvm_SummaryInformation
+	q = queryAll(&summaries)
+	if err := wmiQueryNamespace(q, &summaries, hypervVirtualizationNamespace); err != nil {
+		return nil, err
+	}
+
+	for _, summary := range summaries {
+		ch <- prometheus.MustNewConstMetric(
+			c.CPUUsage,
+			prometheus.GaugeValue,
+			float64(summary.ProcessorLoad),
+			summary.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.MemoryAssigned,
+			prometheus.GaugeValue,
+			float64(summary.MemoryUsage)*1024*1024,
+			summary.Name,
+		)
+	}
+
+	var settings []Msvm_ProcessorSettingData
+	q = queryAll(&settings)
+	if err := wmiQueryNamespace(q, &settings, hypervVirtualizationNamespace); err != nil {
+		return nil, err
+	}
+
+	for _, setting := range settings {
+		// Processor settings of snapshots and planned VMs refer to other IDs,
+		// and are skipped.
+		vmID, ok := vmIDs[hypervSettingVMID(setting.InstanceID)]
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.VirtualProcessors,
+			prometheus.GaugeValue,
+			float64(setting.VirtualQuantity),
+			vmID,
+		)
+		// Reservation and Limit are expressed in thousandths of a percent.
+		ch <- prometheus.MustNewConstMetric(
+			c.CPUReservation,
+			prometheus.GaugeValue,
+			float64(setting.Reservation)/1000,
+			vmID,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.CPULimit,
+			prometheus.GaugeValue,
+			float64(setting.Limit)/1000,
+			vmID,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.CPUWeight,
+			prometheus.GaugeValue,
+			float64(setting.Weight),
+			vmID,
+		)
+	}
+
+	return nil, nil
+}
+
+// hypervSettingVMID extracts the ID of the virtual machine from the InstanceID
+// of one of its setting data, of the form Microsoft:<VM ID>\<device ID>\...
+func hypervSettingVMID(instanceID string) string {
+	id := strings.TrimPrefix(instanceID, "Microsoft:")
+	if i := strings.Index(id, `\`); i >= 0 {
+		id = id[:i]
+	}
+	return strings.ToUpper(id)
+}
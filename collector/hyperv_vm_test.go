@@ -0,0 +1,21 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestHypervSettingVMID(t *testing.T) {
+	cases := map[string]string{
+		`Microsoft:6EE5A3E2-1F3A-4B8C-9E2D-6C0E9F4C1A2B\b637f346-6a0e-4dec-af52-bd70cb80a21d\0`: "6EE5A3E2-1F3A-4B8C-9E2D-6C0E9F4C1A2B",
+		`Microsoft:6ee5a3e2-1f3a-4b8c-9e2d-6c0e9f4c1a2b`:                                        "6EE5A3E2-1F3A-4B8C-9E2D-6C0E9F4C1A2B",
+	}
+	for in, out := range cases {
+		if got := hypervSettingVMID(in); got != out {
+			t.Errorf("expected %q, got %q", out, got)
+		}
+	}
+}
+
+func BenchmarkHyperVVMCollector(b *testing.B) {
+	benchmarkCollector(b, "hyperv_vm", NewHyperVVMCollector)
+}
@@ -9,7 +9,6 @@ import (
 
 	"golang.org/x/sys/windows/registry"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -19,6 +18,8 @@ func init() {
 	registerCollector("iis", NewIISCollector)
 }
 
+var iisLog = log.With("collector", "iis")
+
 type simple_version struct {
 	major uint64
 	minor uint64
@@ -27,28 +28,28 @@ type simple_version struct {
 func getIISVersion() simple_version {
 	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\InetStp\`, registry.QUERY_VALUE)
 	if err != nil {
-		log.Warn("Couldn't open registry to determine IIS version:", err)
+		iisLog.Warn("Couldn't open registry to determine IIS version:", err)
 		return simple_version{}
 	}
 	defer func() {
 		err = k.Close()
 		if err != nil {
-			log.Warnf("Failed to close registry key: %v", err)
+			iisLog.Warnf("Failed to close registry key: %v", err)
 		}
 	}()
 
 	major, _, err := k.GetIntegerValue("MajorVersion")
 	if err != nil {
-		log.Warn("Couldn't open registry to determine IIS version:", err)
+		iisLog.Warn("Couldn't open registry to determine IIS version:", err)
 		return simple_version{}
 	}
 	minor, _, err := k.GetIntegerValue("MinorVersion")
 	if err != nil {
-		log.Warn("Couldn't open registry to determine IIS version:", err)
+		iisLog.Warn("Couldn't open registry to determine IIS version:", err)
 		return simple_version{}
 	}
 
-	log.Debugf("Detected IIS %d.%d\n", major, minor)
+	iisLog.Debugf("Detected IIS %d.%d\n", major, minor)
 
 	return simple_version{
 		major: major,
@@ -820,7 +821,7 @@ func NewIISCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *IISCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting iis metrics:", desc, err)
+		iisLog.Error("failed collecting iis metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -1001,7 +1002,7 @@ var workerProcessNameExtractor = regexp.MustCompile(`^(\d+)_(.+)$`)
 func (c *IISCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_W3SVC_WebService
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 
@@ -1254,7 +1255,7 @@ func (c *IISCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, e
 
 	var dst2 []Win32_PerfRawData_APPPOOLCountersProvider_APPPOOLWAS
 	q2 := queryAll(&dst2)
-	if err := wmi.Query(q2, &dst2); err != nil {
+	if err := wmiQuery(q2, &dst2); err != nil {
 		return nil, err
 	}
 
@@ -1371,7 +1372,7 @@ func (c *IISCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, e
 
 	var dst_worker []Win32_PerfRawData_W3SVCW3WPCounterProvider_W3SVCW3WP
 	q = queryAll(&dst_worker)
-	if err := wmi.Query(q, &dst_worker); err != nil {
+	if err := wmiQuery(q, &dst_worker); err != nil {
 		return nil, err
 	}
 	for _, app := range dst_worker {
@@ -1653,7 +1654,7 @@ func (c *IISCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, e
 	if c.iis_version.major >= 8 {
 		var dst_worker_iis8 []Win32_PerfRawData_W3SVCW3WPCounterProvider_W3SVCW3WP_IIS8
 		q = queryAllForClass(&dst_worker_iis8, "Win32_PerfRawData_W3SVCW3WPCounterProvider_W3SVCW3WP")
-		if err := wmi.Query(q, &dst_worker_iis8); err != nil {
+		if err := wmiQuery(q, &dst_worker_iis8); err != nil {
 			return nil, err
 		}
 		for _, app := range dst_worker_iis8 {
@@ -1736,7 +1737,7 @@ func (c *IISCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, e
 
 	var dst_cache []Win32_PerfRawData_W3SVC_WebServiceCache
 	q = queryAll(&dst_cache)
-	if err := wmi.Query(q, &dst_cache); err != nil {
+	if err := wmiQuery(q, &dst_cache); err != nil {
 		return nil, err
 	}
 
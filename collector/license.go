@@ -0,0 +1,110 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("license", newLicenseCollector)
+}
+
+var licenseLog = log.With("collector", "license")
+
+// windowsApplicationID is the ApplicationID of the Windows products, as
+// opposed to Office and other licensed software.
+const windowsApplicationID = "55c92734-d682-4d71-983e-d6ec3f16059f"
+
+// licenseStatuses maps SoftwareLicensingProduct.LicenseStatus values to
+// label values. The different grace periods are reported as one.
+var licenseStatuses = map[uint32]string{
+	0: "unlicensed",
+	1: "licensed",
+	2: "grace", // Out-Of-Box grace period
+	3: "grace", // Out-Of-Tolerance grace period
+	4: "grace", // Non-genuine grace period
+	5: "notified",
+	6: "grace", // Extended grace period
+}
+
+var allLicenseStatuses = []string{"licensed", "notified", "grace", "unlicensed"}
+
+// A LicenseCollector is a Prometheus collector for the activation status of
+// Windows, from WMI SoftwareLicensingProduct
+type LicenseCollector struct {
+	Status             *prometheus.Desc
+	GracePeriodMinutes *prometheus.Desc
+}
+
+func newLicenseCollector() (Collector, error) {
+	const subsystem = "license"
+
+	return &LicenseCollector{
+		Status: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "status"),
+			"The license status of the Windows product, 1 if the current status, 0 otherwise (LicenseStatus)",
+			[]string{"name", "status"},
+			nil,
+		),
+		GracePeriodMinutes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "grace_period_minutes"),
+			"Remaining time before the grace period of the Windows product expires, in minutes (GracePeriodRemaining)",
+			[]string{"name"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *LicenseCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		licenseLog.Error("failed collecting license metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// SoftwareLicensingProduct docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/sppwmi/softwarelicensingproduct
+type SoftwareLicensingProduct struct {
+	Name                 string
+	LicenseStatus        uint32
+	GracePeriodRemaining uint32
+}
+
+func (c *LicenseCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	// Only the Windows products with a product key installed are relevant,
+	// the class otherwise lists every SKU known to the host.
+	var dst []SoftwareLicensingProduct
+	q := queryAllWhere(&dst, "ApplicationID = '"+windowsApplicationID+"' AND PartialProductKey IS NOT NULL")
+	if err := wmiQuery(q, &dst); err != nil {
+		return c.Status, err
+	}
+
+	for _, product := range dst {
+		status, ok := licenseStatuses[product.LicenseStatus]
+		if !ok {
+			licenseLog.Debugf("Unknown license status %d of %s", product.LicenseStatus, product.Name)
+		}
+		for _, s := range allLicenseStatuses {
+			ch <- prometheus.MustNewConstMetric(
+				c.Status,
+				prometheus.GaugeValue,
+				boolToFloat(s == status),
+				product.Name,
+				s,
+			)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.GracePeriodMinutes,
+			prometheus.GaugeValue,
+			float64(product.GracePeriodRemaining),
+			product.Name,
+		)
+	}
+
+	return nil, nil
+}
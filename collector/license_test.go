@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkLicenseCollector(b *testing.B) {
+	benchmarkCollector(b, "license", newLicenseCollector)
+}
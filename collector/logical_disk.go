@@ -15,6 +15,8 @@ func init() {
 	registerCollector("logical_disk", NewLogicalDiskCollector, "LogicalDisk")
 }
 
+var logicalDiskLog = log.With("collector", "logical_disk")
+
 var (
 	volumeWhitelist = kingpin.Flag(
 		"collector.logical_disk.volume-whitelist",
@@ -131,21 +133,21 @@ func NewLogicalDiskCollector() (Collector, error) {
 
 		ReadLatency: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "read_latency_seconds_total"),
-			"Shows the average time, in seconds, of a read operation from the disk (LogicalDisk.AvgDiskSecPerRead)",
+			"Cumulative time, in seconds, spent in read operations from the disk (LogicalDisk.AvgDiskSecPerRead)",
 			[]string{"volume"},
 			nil,
 		),
 
 		WriteLatency: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "write_latency_seconds_total"),
-			"Shows the average time, in seconds, of a write operation to the disk (LogicalDisk.AvgDiskSecPerWrite)",
+			"Cumulative time, in seconds, spent in write operations to the disk (LogicalDisk.AvgDiskSecPerWrite)",
 			[]string{"volume"},
 			nil,
 		),
 
 		ReadWriteLatency: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "read_write_latency_seconds_total"),
-			"Shows the time, in seconds, of the average disk transfer (LogicalDisk.AvgDiskSecPerTransfer)",
+			"Cumulative time, in seconds, spent in read and write operations on the disk (LogicalDisk.AvgDiskSecPerTransfer)",
 			[]string{"volume"},
 			nil,
 		),
@@ -159,7 +161,7 @@ func NewLogicalDiskCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *LogicalDiskCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting logical_disk metrics:", desc, err)
+		logicalDiskLog.Error("failed collecting logical_disk metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -4,8 +4,8 @@ package collector
 
 import (
 	"errors"
+	"time"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -14,9 +14,12 @@ func init() {
 	registerCollector("logon", NewLogonCollector)
 }
 
+var logonLog = log.With("collector", "logon")
+
 // A LogonCollector is a Prometheus collector for WMI metrics
 type LogonCollector struct {
-	LogonType *prometheus.Desc
+	LogonType            *prometheus.Desc
+	SessionOldestSeconds *prometheus.Desc
 }
 
 // NewLogonCollector ...
@@ -30,6 +33,12 @@ func NewLogonCollector() (Collector, error) {
 			[]string{"status"},
 			nil,
 		),
+		SessionOldestSeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "session_oldest_seconds"),
+			"Age of the oldest local or remote interactive logon session, in seconds (LogonSession.StartTime)",
+			nil,
+			nil,
+		),
 	}, nil
 }
 
@@ -37,7 +46,7 @@ func NewLogonCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *LogonCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting user metrics:", desc, err)
+		logonLog.Error("failed collecting user metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -47,12 +56,28 @@ func (c *LogonCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric
 // - https://docs.microsoft.com/en-us/windows/win32/cimwin32prov/win32-logonsession
 type Win32_LogonSession struct {
 	LogonType uint32
+	StartTime time.Time
+}
+
+// oldestInteractiveLogon returns the start time of the oldest local or remote
+// interactive logon session, if any.
+func oldestInteractiveLogon(sessions []Win32_LogonSession) (time.Time, bool) {
+	var oldest time.Time
+	for _, session := range sessions {
+		switch session.LogonType {
+		case 2, 10, 11, 12: // Interactive, RemoteInteractive and their cached variants
+			if !session.StartTime.IsZero() && (oldest.IsZero() || session.StartTime.Before(oldest)) {
+				oldest = session.StartTime
+			}
+		}
+	}
+	return oldest, !oldest.IsZero()
 }
 
 func (c *LogonCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_LogonSession
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return nil, err
 	}
 	if len(dst) == 0 {
@@ -185,7 +210,7 @@ func (c *LogonCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc,
 	ch <- prometheus.MustNewConstMetric(
 		c.LogonType,
 		prometheus.GaugeValue,
-		float64(remoteinteractive),
+		float64(cachedremoteinteractive),
 		"cached_remote_interactive",
 	)
 
@@ -195,5 +220,13 @@ func (c *LogonCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc,
 		float64(cachedunlock),
 		"cached_unlock",
 	)
+
+	if oldest, ok := oldestInteractiveLogon(dst); ok {
+		ch <- prometheus.MustNewConstMetric(
+			c.SessionOldestSeconds,
+			prometheus.GaugeValue,
+			time.Since(oldest).Seconds(),
+		)
+	}
 	return nil, nil
 }
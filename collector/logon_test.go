@@ -2,9 +2,26 @@ package collector
 
 import (
 	"testing"
+	"time"
 )
 
 func BenchmarkLogonCollector(b *testing.B) {
 	// No context name required as collector source is WMI
 	benchmarkCollector(b, "", NewLogonCollector)
 }
+
+func TestOldestInteractiveLogon(t *testing.T) {
+	now := time.Now()
+	sessions := []Win32_LogonSession{
+		{LogonType: 5, StartTime: now.Add(-48 * time.Hour)},
+		{LogonType: 2, StartTime: now.Add(-time.Hour)},
+		{LogonType: 10, StartTime: now.Add(-2 * time.Hour)},
+		{LogonType: 10},
+	}
+	if oldest, ok := oldestInteractiveLogon(sessions); !ok || !oldest.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("expected the remote interactive session, got %v, %v", oldest, ok)
+	}
+	if _, ok := oldestInteractiveLogon(sessions[:1]); ok {
+		t.Error("expected no interactive session")
+	}
+}
@@ -6,6 +6,7 @@
 package collector
 
 import (
+	"github.com/prometheus-community/windows_exporter/headers/memoryapi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -14,6 +15,8 @@ func init() {
 	registerCollector("memory", NewMemoryCollector, "Memory")
 }
 
+var memoryLog = log.With("collector", "memory")
+
 // A MemoryCollector is a Prometheus collector for perflib Memory metrics
 type MemoryCollector struct {
 	AvailableBytes                  *prometheus.Desc
@@ -22,6 +25,9 @@ type MemoryCollector struct {
 	CacheFaultsTotal                *prometheus.Desc
 	CommitLimit                     *prometheus.Desc
 	CommittedBytes                  *prometheus.Desc
+	CommittedRatio                  *prometheus.Desc
+	FileCacheLimitBytes             *prometheus.Desc
+	FileCacheLimitHard              *prometheus.Desc
 	DemandZeroFaultsTotal           *prometheus.Desc
 	FreeAndZeroPageListBytes        *prometheus.Desc
 	FreeSystemPageTableEntries      *prometheus.Desc
@@ -40,6 +46,7 @@ type MemoryCollector struct {
 	StandbyCacheCoreBytes           *prometheus.Desc
 	StandbyCacheNormalPriorityBytes *prometheus.Desc
 	StandbyCacheReserveBytes        *prometheus.Desc
+	StandbyCacheBytes               *prometheus.Desc
 	SystemCacheResidentBytes        *prometheus.Desc
 	SystemCodeResidentBytes         *prometheus.Desc
 	SystemCodeTotalBytes            *prometheus.Desc
@@ -92,6 +99,12 @@ func NewMemoryCollector() (Collector, error) {
 			nil,
 			nil,
 		),
+		CommittedRatio: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "committed_ratio"),
+			"Ratio of committed virtual memory to the commit limit (CommittedBytes / CommitLimit)",
+			nil,
+			nil,
+		),
 		DemandZeroFaultsTotal: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "demand_zero_faults_total"),
 			"The number of zeroed pages required to satisfy faults. Zeroed pages, pages emptied of previously stored data and filled with zeros, are a security"+
@@ -202,6 +215,24 @@ func NewMemoryCollector() (Collector, error) {
 			nil,
 			nil,
 		),
+		StandbyCacheBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "standby_cache_bytes"),
+			"Memory on the standby lists, cached but immediately available for allocation, as shown as Standby by Resource Monitor (sum of the standby cache counters)",
+			nil,
+			nil,
+		),
+		FileCacheLimitBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "file_cache_limit_bytes"),
+			"Minimum and maximum size of the working set of the system file cache (GetSystemFileCacheSize)",
+			[]string{"limit"},
+			nil,
+		),
+		FileCacheLimitHard: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "file_cache_limit_hard"),
+			"Whether the limit of the size of the system file cache is enforced, rather than only applied under memory pressure (GetSystemFileCacheSize)",
+			[]string{"limit"},
+			nil,
+		),
 		SystemCacheResidentBytes: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "system_cache_resident_bytes"),
 			"(SystemCacheResidentBytes)",
@@ -257,7 +288,7 @@ func NewMemoryCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *MemoryCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting memory metrics:", desc, err)
+		memoryLog.Error("failed collecting memory metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -342,6 +373,14 @@ func (c *MemoryCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metri
 		dst[0].CommittedBytes,
 	)
 
+	if dst[0].CommitLimit > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			c.CommittedRatio,
+			prometheus.GaugeValue,
+			dst[0].CommittedBytes/dst[0].CommitLimit,
+		)
+	}
+
 	ch <- prometheus.MustNewConstMetric(
 		c.DemandZeroFaultsTotal,
 		prometheus.GaugeValue,
@@ -450,6 +489,12 @@ func (c *MemoryCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metri
 		dst[0].StandbyCacheReserveBytes,
 	)
 
+	ch <- prometheus.MustNewConstMetric(
+		c.StandbyCacheBytes,
+		prometheus.GaugeValue,
+		dst[0].StandbyCacheCoreBytes+dst[0].StandbyCacheNormalPriorityBytes+dst[0].StandbyCacheReserveBytes,
+	)
+
 	ch <- prometheus.MustNewConstMetric(
 		c.SystemCacheResidentBytes,
 		prometheus.GaugeValue,
@@ -498,5 +543,31 @@ func (c *MemoryCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metri
 		dst[0].WriteCopiesPersec,
 	)
 
+	fileCache, err := memoryapi.GetSystemFileCacheSize()
+	if err != nil {
+		return c.FileCacheLimitBytes, err
+	}
+	for _, limit := range []struct {
+		name  string
+		bytes uint64
+		hard  bool
+	}{
+		{"min", fileCache.Minimum, fileCache.MinimumHard},
+		{"max", fileCache.Maximum, fileCache.MaximumHard},
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			c.FileCacheLimitBytes,
+			prometheus.GaugeValue,
+			float64(limit.bytes),
+			limit.name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.FileCacheLimitHard,
+			prometheus.GaugeValue,
+			boolToFloat(limit.hard),
+			limit.name,
+		)
+	}
+
 	return nil, nil
 }
@@ -0,0 +1,195 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("mscluster", newMSClusterCollector)
+}
+
+var msclusterLog = log.With("collector", "mscluster")
+
+const msclusterNamespace = `root\MSCluster`
+
+// A msclusterCollector is a Prometheus collector for WMI MSCluster_Node, MSCluster_Resource and MSCluster_ResourceGroup metrics
+type msclusterCollector struct {
+	NodeState      *prometheus.Desc
+	ResourceState  *prometheus.Desc
+	GroupOwnerNode *prometheus.Desc
+}
+
+func newMSClusterCollector() (Collector, error) {
+	const subsystem = "mscluster"
+
+	return &msclusterCollector{
+		NodeState: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "node_state"),
+			"The state of the cluster node (State)",
+			[]string{"node", "state"},
+			nil,
+		),
+		ResourceState: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "resource_state"),
+			"The state of the cluster resource (State)",
+			[]string{"resource", "state"},
+			nil,
+		),
+		GroupOwnerNode: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "group_owner_node"),
+			"The node owning the resource group, always 1 (OwnerNode)",
+			[]string{"group", "node"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *msclusterCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		msclusterLog.Error("failed collecting mscluster metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// MSCluster_Node docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/cluswmi/mscluster-node
+type MSCluster_Node struct {
+	Name  string
+	State uint32
+}
+
+// MSCluster_Resource docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/cluswmi/mscluster-resource
+type MSCluster_Resource struct {
+	Name  string
+	State uint32
+}
+
+// MSCluster_ResourceGroup docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/cluswmi/mscluster-resourcegroup
+type MSCluster_ResourceGroup struct {
+	Name      string
+	OwnerNode string
+}
+
+var (
+	allMSClusterNodeStates = []string{
+		"up",
+		"down",
+		"paused",
+		"joining",
+		"unknown",
+	}
+	msclusterNodeStateValues = map[uint32]string{
+		0: "up",
+		1: "down",
+		2: "paused",
+		3: "joining",
+	}
+	allMSClusterResourceStates = []string{
+		"inherited",
+		"initializing",
+		"online",
+		"offline",
+		"failed",
+		"pending",
+		"online_pending",
+		"offline_pending",
+		"unknown",
+	}
+	msclusterResourceStateValues = map[uint32]string{
+		0:   "inherited",
+		1:   "initializing",
+		2:   "online",
+		3:   "offline",
+		4:   "failed",
+		128: "pending",
+		129: "online_pending",
+		130: "offline_pending",
+	}
+)
+
+// msclusterState returns the name of state in values, or "unknown" for the
+// states missing from it, including the -1 the classes use for unknown.
+func msclusterState(values map[uint32]string, state uint32) string {
+	if s, ok := values[state]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+func (c *msclusterCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var nodes []MSCluster_Node
+	q := queryAll(&nodes)
+	if err := wmiQueryNamespace(q, &nodes, msclusterNamespace); err != nil {
+		if isWMINotFoundError(err) {
+			msclusterLog.Debugf("Failover cluster namespace not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, node := range nodes {
+		currentState := msclusterState(msclusterNodeStateValues, node.State)
+		for _, state := range allMSClusterNodeStates {
+			isCurrentState := 0.0
+			if state == currentState {
+				isCurrentState = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.NodeState,
+				prometheus.GaugeValue,
+				isCurrentState,
+				node.Name,
+				state,
+			)
+		}
+	}
+
+	var resources []MSCluster_Resource
+	q = queryAll(&resources)
+	if err := wmiQueryNamespace(q, &resources, msclusterNamespace); err != nil {
+		return nil, err
+	}
+
+	for _, resource := range resources {
+		currentState := msclusterState(msclusterResourceStateValues, resource.State)
+		for _, state := range allMSClusterResourceStates {
+			isCurrentState := 0.0
+			if state == currentState {
+				isCurrentState = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.ResourceState,
+				prometheus.GaugeValue,
+				isCurrentState,
+				resource.Name,
+				state,
+			)
+		}
+	}
+
+	var groups []MSCluster_ResourceGroup
+	q = queryAll(&groups)
+	if err := wmiQueryNamespace(q, &groups, msclusterNamespace); err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		ch <- prometheus.MustNewConstMetric(
+			c.GroupOwnerNode,
+			prometheus.GaugeValue,
+			1.0,
+			group.Name,
+			group.OwnerNode,
+		)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,29 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestMSClusterState(t *testing.T) {
+	cases := []struct {
+		values   map[uint32]string
+		state    uint32
+		expected string
+	}{
+		{msclusterNodeStateValues, 0, "up"},
+		{msclusterNodeStateValues, 2, "paused"},
+		{msclusterNodeStateValues, 0xFFFFFFFF, "unknown"},
+		{msclusterResourceStateValues, 4, "failed"},
+		{msclusterResourceStateValues, 129, "online_pending"},
+		{msclusterResourceStateValues, 5, "unknown"},
+	}
+	for _, c := range cases {
+		if got := msclusterState(c.values, c.state); got != c.expected {
+			t.Errorf("msclusterState(%d) = %q, expected %q", c.state, got, c.expected)
+		}
+	}
+}
+
+func BenchmarkMSClusterCollector(b *testing.B) {
+	benchmarkCollector(b, "mscluster", newMSClusterCollector)
+}
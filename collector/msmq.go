@@ -5,7 +5,6 @@ package collector
 import (
 	"strings"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -15,8 +14,14 @@ func init() {
 	registerCollector("msmq", NewMSMQCollector)
 }
 
+var msmqLog = log.With("collector", "msmq")
+
 var (
-	msmqWhereClause = kingpin.Flag("collector.msmq.msmq-where", "WQL 'where' clause to use in WMI metrics query. Limits the response to the msmqs you specify and reduces the size of the response.").String()
+	msmqWhereClause         = kingpin.Flag("collector.msmq.msmq-where", "WQL 'where' clause to use in WMI metrics query. Limits the response to the msmqs you specify and reduces the size of the response.").String()
+	msmqExcludeSystemQueues = kingpin.Flag(
+		"collector.msmq.exclude-system-queues",
+		"Exclude the system queues, such as the dead-letter and outgoing order queues, and the Computer Queues total.",
+	).Default("false").Bool()
 )
 
 // A Win32_PerfRawData_MSMQ_MSMQQueueCollector is a Prometheus collector for WMI Win32_PerfRawData_MSMQ_MSMQQueue metrics
@@ -26,7 +31,8 @@ type Win32_PerfRawData_MSMQ_MSMQQueueCollector struct {
 	MessagesinJournalQueue *prometheus.Desc
 	MessagesinQueue        *prometheus.Desc
 
-	queryWhereClause string
+	queryWhereClause    string
+	excludeSystemQueues bool
 }
 
 // NewWin32_PerfRawData_MSMQ_MSMQQueueCollector ...
@@ -34,7 +40,7 @@ func NewMSMQCollector() (Collector, error) {
 	const subsystem = "msmq"
 
 	if *msmqWhereClause == "" {
-		log.Warn("No where-clause specified for msmq collector. This will generate a very large number of metrics!")
+		msmqLog.Warn("No where-clause specified for msmq collector. This will generate a very large number of metrics!")
 	}
 
 	return &Win32_PerfRawData_MSMQ_MSMQQueueCollector{
@@ -62,7 +68,8 @@ func NewMSMQCollector() (Collector, error) {
 			[]string{"name"},
 			nil,
 		),
-		queryWhereClause: *msmqWhereClause,
+		queryWhereClause:    *msmqWhereClause,
+		excludeSystemQueues: *msmqExcludeSystemQueues,
 	}, nil
 }
 
@@ -70,7 +77,7 @@ func NewMSMQCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *Win32_PerfRawData_MSMQ_MSMQQueueCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting msmq metrics:", desc, err)
+		msmqLog.Error("failed collecting msmq metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -85,14 +92,34 @@ type Win32_PerfRawData_MSMQ_MSMQQueue struct {
 	MessagesinQueue        uint64
 }
 
+// isMSMQSystemQueue reports whether the instance is a queue used by MSMQ
+// itself rather than by applications: the system queues of the computer
+// (e.g. "host\system$;deadletter"), its private system queues, whose names
+// end with a dollar sign (e.g. "host\private$\order_queue$"), and the
+// "Computer Queues" instance totalling all the queues.
+func isMSMQSystemQueue(name string) bool {
+	name = strings.ToLower(name)
+	return name == "computer queues" ||
+		strings.Contains(name, `\system$;`) ||
+		strings.HasSuffix(name, "$")
+}
+
 func (c *Win32_PerfRawData_MSMQ_MSMQQueueCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_MSMQ_MSMQQueue
 	q := queryAllWhere(&dst, c.queryWhereClause)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
+		if isWMINotFoundError(err) {
+			msmqLog.Debugf("MSMQ performance counters not found, Message Queuing is likely not installed. Skipping: %v", err)
+			return nil, nil
+		}
 		return nil, err
 	}
 
 	for _, msmq := range dst {
+		if c.excludeSystemQueues && isMSMQSystemQueue(msmq.Name) {
+			continue
+		}
+
 		ch <- prometheus.MustNewConstMetric(
 			c.BytesinJournalQueue,
 			prometheus.GaugeValue,
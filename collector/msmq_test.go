@@ -8,3 +8,20 @@ func BenchmarkMsmqCollector(b *testing.B) {
 	// No context name required as collector source is WMI
 	benchmarkCollector(b, "", NewMSMQCollector)
 }
+
+func TestIsMSMQSystemQueue(t *testing.T) {
+	for name, expected := range map[string]bool{
+		`Computer Queues`:               true,
+		`HOST\private$\order_queue$`:    true,
+		`host\private$\admin_queue$`:    true,
+		`host\system$;DEADLETTER`:       true,
+		`host\system$;JOURNAL`:          true,
+		`host\private$\orders`:          false,
+		`host\orders`:                   false,
+		`host\private$\orders$payments`: false,
+	} {
+		if actual := isMSMQSystemQueue(name); actual != expected {
+			t.Errorf("isMSMQSystemQueue(%q): expected %t, got %t", name, expected, actual)
+		}
+	}
+}
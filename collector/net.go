@@ -15,6 +15,8 @@ func init() {
 	registerCollector("net", NewNetworkCollector, "Network Interface")
 }
 
+var netLog = log.With("collector", "net")
+
 var (
 	nicWhitelist = kingpin.Flag(
 		"collector.net.nic-whitelist",
@@ -133,7 +135,7 @@ func NewNetworkCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *NetworkCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting net metrics:", desc, err)
+		netLog.Error("failed collecting net metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -0,0 +1,172 @@
+// +build windows
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+)
+
+func init() {
+	registerCollector("net_config", newNetConfigCollector)
+}
+
+var netConfigLog = log.With("collector", "net_config")
+
+const (
+	tcpipLinkageKey         = `SYSTEM\CurrentControlSet\Services\Tcpip\Linkage`
+	networkProviderOrderKey = `SYSTEM\CurrentControlSet\Control\NetworkProvider\Order`
+)
+
+// netbiosDisabled is the TcpipNetbiosOptions value of adapters with NetBIOS
+// over TCP/IP disabled. 0 uses the setting of the DHCP server, which enables
+// NetBIOS unless told otherwise, and 1 enables it.
+const netbiosDisabled = 2
+
+// A NetConfigCollector is a Prometheus collector for the legacy network
+// configuration: NetBIOS over TCP/IP from WMI Win32_NetworkAdapterConfiguration,
+// and the adapter binding and network provider orders from the registry
+type NetConfigCollector struct {
+	NetbiosEnabled *prometheus.Desc
+	BindingOrder   *prometheus.Desc
+	ProviderOrder  *prometheus.Desc
+}
+
+func newNetConfigCollector() (Collector, error) {
+	const subsystem = "net_config"
+
+	return &NetConfigCollector{
+		NetbiosEnabled: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "netbios_enabled"),
+			"Whether NetBIOS over TCP/IP is enabled on the network adapter (TcpipNetbiosOptions)",
+			[]string{"nic"},
+			nil,
+		),
+		BindingOrder: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "binding_order"),
+			"Position of the network adapter in the TCP/IP binding order, starting at 1",
+			[]string{"nic"},
+			nil,
+		),
+		ProviderOrder: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "provider_order"),
+			"Position of the network provider in the provider order, starting at 1",
+			[]string{"provider"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *NetConfigCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		netConfigLog.Error("failed collecting net_config metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Win32_NetworkAdapterConfiguration docs:
+// - https://docs.microsoft.com/en-us/windows/win32/cimwin32prov/win32-networkadapterconfiguration
+type Win32_NetworkAdapterConfiguration struct {
+	Description         string
+	SettingID           string
+	TcpipNetbiosOptions *uint32
+}
+
+func (c *NetConfigCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []Win32_NetworkAdapterConfiguration
+	if err := wmiQuery(queryAllWhere(&dst, "IPEnabled = TRUE"), &dst); err != nil {
+		return c.NetbiosEnabled, err
+	}
+
+	// The description of the adapter is the instance name of its performance
+	// counters, mangled the same way as in the net collector.
+	nics := make(map[string]string, len(dst))
+	for _, adapter := range dst {
+		nic := mangleNetworkName(adapter.Description)
+		nics[strings.ToLower(adapter.SettingID)] = nic
+
+		if adapter.TcpipNetbiosOptions == nil {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.NetbiosEnabled,
+			prometheus.GaugeValue,
+			boolToFloat(*adapter.TcpipNetbiosOptions != netbiosDisabled),
+			nic,
+		)
+	}
+
+	bind, err := readMultiStringValue(tcpipLinkageKey, "Bind")
+	if err != nil {
+		return c.BindingOrder, err
+	}
+	for i, nic := range bindingOrder(bind, nics) {
+		ch <- prometheus.MustNewConstMetric(
+			c.BindingOrder,
+			prometheus.GaugeValue,
+			float64(i+1),
+			nic,
+		)
+	}
+
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, networkProviderOrderKey, registry.QUERY_VALUE)
+	if err != nil {
+		return c.ProviderOrder, err
+	}
+	defer k.Close()
+	order, _, err := k.GetStringValue("ProviderOrder")
+	if err != nil {
+		return c.ProviderOrder, err
+	}
+	for i, provider := range parseProviderOrder(order) {
+		ch <- prometheus.MustNewConstMetric(
+			c.ProviderOrder,
+			prometheus.GaugeValue,
+			float64(i+1),
+			provider,
+		)
+	}
+
+	return nil, nil
+}
+
+func readMultiStringValue(path, name string) ([]string, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, path, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Close()
+	values, _, err := k.GetStringsValue(name)
+	return values, err
+}
+
+// bindingOrder returns the names of the adapters in the order of the
+// \Device\{GUID} entries of the Tcpip Linkage Bind value. Entries which don't
+// belong to an IP enabled adapter, like the WAN miniports, are left out.
+func bindingOrder(bind []string, nics map[string]string) []string {
+	order := make([]string, 0, len(nics))
+	for _, device := range bind {
+		guid := device[strings.LastIndex(device, `\`)+1:]
+		if nic, ok := nics[strings.ToLower(guid)]; ok {
+			order = append(order, nic)
+		}
+	}
+	return order
+}
+
+// parseProviderOrder splits the comma separated ProviderOrder value
+func parseProviderOrder(order string) []string {
+	var providers []string
+	for _, p := range strings.Split(order, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			providers = append(providers, p)
+		}
+	}
+	return providers
+}
@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func BenchmarkNetConfigCollector(b *testing.B) {
+	benchmarkCollector(b, "net_config", newNetConfigCollector)
+}
+
+func TestBindingOrder(t *testing.T) {
+	nics := map[string]string{
+		"{4d36e972-e325-11ce-bfc1-08002be10318}": "Intel_R__Ethernet_Connection",
+		"{8a2f1c3e-0b1d-4c5e-9f6a-7b8c9d0e1f2a}": "Hyper_V_Virtual_Ethernet_Adapter",
+	}
+	bind := []string{
+		`\Device\{8A2F1C3E-0B1D-4C5E-9F6A-7B8C9D0E1F2A}`,
+		`\Device\NdisWanIp`,
+		`\Device\{4D36E972-E325-11CE-BFC1-08002BE10318}`,
+	}
+
+	got := bindingOrder(bind, nics)
+	want := []string{"Hyper_V_Virtual_Ethernet_Adapter", "Intel_R__Ethernet_Connection"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestParseProviderOrder(t *testing.T) {
+	got := parseProviderOrder("RDPNP,LanmanWorkstation, webclient,")
+	want := []string{"RDPNP", "LanmanWorkstation", "webclient"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
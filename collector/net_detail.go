@@ -0,0 +1,145 @@
+// +build windows
+
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/headers/iphlpapi"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func init() {
+	registerCollector("net_detail", NewNetworkDetailCollector)
+}
+
+var netDetailLog = log.With("collector", "net_detail")
+
+var (
+	netDetailAllInterfaces = kingpin.Flag(
+		"collector.net_detail.all-interfaces",
+		"Include loopback and tunnel interfaces.",
+	).Default("false").Bool()
+
+	// Characters replaced in the description of an interface to form the
+	// instance name of its Network Interface performance counters.
+	perflibNetworkNameReplacer = strings.NewReplacer("(", "[", ")", "]", "#", "_", "/", "_", "\\", "_")
+)
+
+// A NetworkDetailCollector is a Prometheus collector for GetIfTable2 network interface error metrics
+type NetworkDetailCollector struct {
+	InErrorsTotal    *prometheus.Desc
+	OutErrorsTotal   *prometheus.Desc
+	InDiscardsTotal  *prometheus.Desc
+	OutDiscardsTotal *prometheus.Desc
+
+	allInterfaces       bool
+	nicWhitelistPattern *regexp.Regexp
+	nicBlacklistPattern *regexp.Regexp
+}
+
+// NewNetworkDetailCollector ...
+func NewNetworkDetailCollector() (Collector, error) {
+	const subsystem = "net"
+
+	return &NetworkDetailCollector{
+		InErrorsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "in_errors_total"),
+			"Number of incoming packets discarded because of errors (MIB_IF_ROW2.InErrors)",
+			[]string{"nic"},
+			nil,
+		),
+		OutErrorsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "out_errors_total"),
+			"Number of outgoing packets discarded because of errors (MIB_IF_ROW2.OutErrors)",
+			[]string{"nic"},
+			nil,
+		),
+		InDiscardsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "in_discards_total"),
+			"Number of incoming packets discarded without errors, e.g. for lack of buffer space (MIB_IF_ROW2.InDiscards)",
+			[]string{"nic"},
+			nil,
+		),
+		OutDiscardsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "out_discards_total"),
+			"Number of outgoing packets discarded without errors, e.g. for lack of buffer space (MIB_IF_ROW2.OutDiscards)",
+			[]string{"nic"},
+			nil,
+		),
+		allInterfaces: *netDetailAllInterfaces,
+		// Share the interface filters of the net collector, so the series line up.
+		nicWhitelistPattern: regexp.MustCompile(fmt.Sprintf("^(?:%s)$", *nicWhitelist)),
+		nicBlacklistPattern: regexp.MustCompile(fmt.Sprintf("^(?:%s)$", *nicBlacklist)),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *NetworkDetailCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		netDetailLog.Error("failed collecting net_detail metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+func (c *NetworkDetailCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	rows, err := iphlpapi.GetIfTable2()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		// Filter interfaces are layered on top of the actual interface, with
+		// the same statistics.
+		if row.InterfaceAndOperStatusFlags&iphlpapi.InterfaceFilter != 0 {
+			continue
+		}
+		if !c.allInterfaces && (row.Type == iphlpapi.IF_TYPE_SOFTWARE_LOOPBACK || row.Type == iphlpapi.IF_TYPE_TUNNEL) {
+			continue
+		}
+
+		instanceName := perflibNetworkNameReplacer.Replace(row.DescriptionString())
+		if c.nicBlacklistPattern.MatchString(instanceName) ||
+			!c.nicWhitelistPattern.MatchString(instanceName) {
+			continue
+		}
+
+		name := mangleNetworkName(instanceName)
+		if name == "" {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.InErrorsTotal,
+			prometheus.CounterValue,
+			float64(row.InErrors),
+			name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.OutErrorsTotal,
+			prometheus.CounterValue,
+			float64(row.OutErrors),
+			name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.InDiscardsTotal,
+			prometheus.CounterValue,
+			float64(row.InDiscards),
+			name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.OutDiscardsTotal,
+			prometheus.CounterValue,
+			float64(row.OutDiscards),
+			name,
+		)
+	}
+
+	return nil, nil
+}
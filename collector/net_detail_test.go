@@ -0,0 +1,22 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestPerflibNetworkNameReplacer(t *testing.T) {
+	cases := map[string]string{
+		"Intel(R) Ethernet Connection (7) I219-LM": "Intel[R] Ethernet Connection [7] I219-LM",
+		"Hyper-V Virtual Ethernet Adapter #2":      "Hyper-V Virtual Ethernet Adapter _2",
+		"WAN Miniport (IP/IPv6)":                   "WAN Miniport [IP_IPv6]",
+	}
+	for description, expected := range cases {
+		if got := perflibNetworkNameReplacer.Replace(description); got != expected {
+			t.Errorf("%q: expected %q, got %q", description, expected, got)
+		}
+	}
+}
+
+func BenchmarkNetworkDetailCollector(b *testing.B) {
+	benchmarkCollector(b, "net_detail", NewNetworkDetailCollector)
+}
@@ -0,0 +1,137 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("nfs", newNFSCollector, "NFS Server")
+}
+
+var nfsLog = log.With("collector", "nfs")
+
+// A NFSCollector is a Prometheus collector for Perflib Server for NFS metrics
+type NFSCollector struct {
+	ServerOperationsTotal *prometheus.Desc
+	ServerActiveClients   *prometheus.Desc
+}
+
+func newNFSCollector() (Collector, error) {
+	const subsystem = "nfs"
+
+	return &NFSCollector{
+		ServerOperationsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "server_operations_total"),
+			"Number of NFS operations processed by the server, by operation",
+			[]string{"operation"},
+			nil,
+		),
+		ServerActiveClients: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "server_active_clients"),
+			"Number of clients with an active session on the server (NFS Server.Active Clients)",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *NFSCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		nfsLog.Error("failed collecting nfs metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib "NFS Server"
+type nfsServer struct {
+	ActiveClients float64 `perflib:"Active Clients"`
+
+	Null        float64 `perflib:"NULL"`
+	Getattr     float64 `perflib:"GETATTR"`
+	Setattr     float64 `perflib:"SETATTR"`
+	Lookup      float64 `perflib:"LOOKUP"`
+	Access      float64 `perflib:"ACCESS"`
+	Readlink    float64 `perflib:"READLINK"`
+	Read        float64 `perflib:"READ"`
+	Write       float64 `perflib:"WRITE"`
+	Create      float64 `perflib:"CREATE"`
+	Mkdir       float64 `perflib:"MKDIR"`
+	Symlink     float64 `perflib:"SYMLINK"`
+	Mknod       float64 `perflib:"MKNOD"`
+	Remove      float64 `perflib:"REMOVE"`
+	Rmdir       float64 `perflib:"RMDIR"`
+	Rename      float64 `perflib:"RENAME"`
+	Link        float64 `perflib:"LINK"`
+	Readdir     float64 `perflib:"READDIR"`
+	Readdirplus float64 `perflib:"READDIRPLUS"`
+	Fsstat      float64 `perflib:"FSSTAT"`
+	Fsinfo      float64 `perflib:"FSINFO"`
+	Pathconf    float64 `perflib:"PATHCONF"`
+	Commit      float64 `perflib:"COMMIT"`
+}
+
+func (c *NFSCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	obj, ok := ctx.perfObjects["NFS Server"]
+	if !ok {
+		nfsLog.Debug("NFS Server counters are not available, Server for NFS is probably not installed. Skipping")
+		return nil, nil
+	}
+
+	var dst []nfsServer
+	if err := unmarshalObject(obj, &dst); err != nil {
+		return nil, err
+	}
+	if len(dst) == 0 {
+		return nil, nil
+	}
+	server := dst[0]
+
+	ch <- prometheus.MustNewConstMetric(
+		c.ServerActiveClients,
+		prometheus.GaugeValue,
+		server.ActiveClients,
+	)
+
+	for _, op := range []struct {
+		name  string
+		value float64
+	}{
+		{"null", server.Null},
+		{"getattr", server.Getattr},
+		{"setattr", server.Setattr},
+		{"lookup", server.Lookup},
+		{"access", server.Access},
+		{"readlink", server.Readlink},
+		{"read", server.Read},
+		{"write", server.Write},
+		{"create", server.Create},
+		{"mkdir", server.Mkdir},
+		{"symlink", server.Symlink},
+		{"mknod", server.Mknod},
+		{"remove", server.Remove},
+		{"rmdir", server.Rmdir},
+		{"rename", server.Rename},
+		{"link", server.Link},
+		{"readdir", server.Readdir},
+		{"readdirplus", server.Readdirplus},
+		{"fsstat", server.Fsstat},
+		{"fsinfo", server.Fsinfo},
+		{"pathconf", server.Pathconf},
+		{"commit", server.Commit},
+	} {
+		ch <- prometheus.MustNewConstMetric(
+			c.ServerOperationsTotal,
+			prometheus.CounterValue,
+			op.value,
+			op.name,
+		)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkNFSCollector(b *testing.B) {
+	benchmarkCollector(b, "nfs", newNFSCollector)
+}
@@ -0,0 +1,83 @@
+// +build windows
+
+package collector
+
+import (
+	"strconv"
+
+	"github.com/prometheus-community/windows_exporter/headers/sysinfoapi"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("numa", newNumaCollector)
+}
+
+var numaLog = log.With("collector", "numa")
+
+// A NumaCollector is a Prometheus collector for the available memory and
+// processors of each NUMA node
+type NumaCollector struct {
+	AvailableBytes *prometheus.Desc
+	ProcessorCount *prometheus.Desc
+}
+
+func newNumaCollector() (Collector, error) {
+	const subsystem = "numa"
+
+	return &NumaCollector{
+		AvailableBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "node_available_bytes"),
+			"Amount of memory available in the NUMA node",
+			[]string{"node"},
+			nil,
+		),
+		ProcessorCount: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "node_processor_count"),
+			"Number of logical processors of the NUMA node",
+			[]string{"node"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *NumaCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		numaLog.Error("failed collecting numa metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+func (c *NumaCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	nodes, err := sysinfoapi.GetNumaNodes()
+	if err != nil {
+		return c.ProcessorCount, err
+	}
+
+	for _, node := range nodes {
+		label := strconv.FormatUint(uint64(node.Number), 10)
+		ch <- prometheus.MustNewConstMetric(
+			c.ProcessorCount,
+			prometheus.GaugeValue,
+			float64(node.ProcessorCount),
+			label,
+		)
+
+		available, err := sysinfoapi.GetNumaAvailableMemoryNode(uint16(node.Number))
+		if err != nil {
+			return c.AvailableBytes, err
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.AvailableBytes,
+			prometheus.GaugeValue,
+			float64(available),
+			label,
+		)
+	}
+
+	return nil, nil
+}
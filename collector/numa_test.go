@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkNumaCollector(b *testing.B) {
+	benchmarkCollector(b, "numa", newNumaCollector)
+}
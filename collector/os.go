@@ -20,6 +20,8 @@ func init() {
 	registerCollector("os", NewOSCollector, "Paging File")
 }
 
+var osLog = log.With("collector", "os")
+
 // A OSCollector is a Prometheus collector for WMI metrics
 type OSCollector struct {
 	OSInformation           *prometheus.Desc
@@ -133,7 +135,7 @@ func NewOSCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *OSCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting os metrics:", desc, err)
+		osLog.Error("failed collecting os metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -0,0 +1,119 @@
+// +build windows
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/headers/sysinfoapi"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("paging", newPagingCollector, "Paging File")
+}
+
+var pagingLog = log.With("collector", "paging")
+
+// A PagingCollector is a Prometheus collector for Perflib Paging File metrics
+type PagingCollector struct {
+	FileUsageRatio     *prometheus.Desc
+	FilePeakUsageRatio *prometheus.Desc
+	FileSizeBytes      *prometheus.Desc
+
+	pageSize float64
+}
+
+func newPagingCollector() (Collector, error) {
+	const subsystem = "paging"
+
+	return &PagingCollector{
+		FileUsageRatio: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "file_usage_ratio"),
+			"Ratio of the paging file in use (Paging File.% Usage)",
+			[]string{"file"},
+			nil,
+		),
+		FilePeakUsageRatio: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "file_peak_usage_ratio"),
+			"Peak ratio of the paging file in use since boot (Paging File.% Usage Peak)",
+			[]string{"file"},
+			nil,
+		),
+		FileSizeBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "file_size_bytes"),
+			"Size of the paging file, in bytes (Paging File.% Usage_Base)",
+			[]string{"file"},
+			nil,
+		),
+		pageSize: float64(sysinfoapi.GetSystemInfo().PageSize),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *PagingCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		pagingLog.Error("failed collecting paging metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib "Paging File". The fractions are counted in pages, their base being
+// the size of the paging file.
+type pagingFile struct {
+	Name string
+
+	Usage         float64 `perflib:"% Usage"`
+	UsageBase     float64 `perflib:"% Usage_Base"`
+	UsagePeak     float64 `perflib:"% Usage Peak"`
+	UsagePeakBase float64 `perflib:"% Usage Peak_Base"`
+}
+
+func (c *PagingCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	obj, ok := ctx.perfObjects["Paging File"]
+	if !ok {
+		// No paging file is configured.
+		pagingLog.Debug("Paging File counters are not available. Skipping")
+		return nil, nil
+	}
+
+	var dst []pagingFile
+	if err := unmarshalObject(obj, &dst); err != nil {
+		return nil, err
+	}
+
+	for _, file := range dst {
+		if strings.EqualFold(file.Name, "_Total") {
+			continue
+		}
+		name := strings.TrimPrefix(file.Name, `\??\`)
+
+		if file.UsageBase > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.FileUsageRatio,
+				prometheus.GaugeValue,
+				file.Usage/file.UsageBase,
+				name,
+			)
+		}
+		if file.UsagePeakBase > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.FilePeakUsageRatio,
+				prometheus.GaugeValue,
+				file.UsagePeak/file.UsagePeakBase,
+				name,
+			)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.FileSizeBytes,
+			prometheus.GaugeValue,
+			file.UsageBase*c.pageSize,
+			name,
+		)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkPagingCollector(b *testing.B) {
+	benchmarkCollector(b, "paging", newPagingCollector)
+}
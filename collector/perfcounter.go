@@ -0,0 +1,200 @@
+// +build windows
+
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/prometheus-community/windows_exporter/headers/pdh"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func init() {
+	registerCollector("perfcounter", NewPerfCounterCollector)
+}
+
+var perfcounterLog = log.With("collector", "perfcounter")
+
+var (
+	perfCounterCounters = kingpin.Flag(
+		"collector.perfcounter.counters",
+		"Counter to expose, as <metric name>=<counter path>, e.g. processor_time=\\Processor(*)\\% Processor Time. May be repeated.",
+	).Strings()
+
+	perfCounterMetricName = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+)
+
+type perfCounterSpec struct {
+	name string
+	path string
+}
+
+type perfCounter struct {
+	perfCounterSpec
+	desc   *prometheus.Desc
+	handle windows.Handle
+}
+
+// A PerfCounterCollector is a Prometheus collector for arbitrary PDH counters
+type PerfCounterCollector struct {
+	// PDH queries aren't safe for concurrent use
+	mu       sync.Mutex
+	query    windows.Handle
+	counters []perfCounter
+}
+
+// NewPerfCounterCollector ...
+func NewPerfCounterCollector() (Collector, error) {
+	const subsystem = "perfcounter"
+
+	specs, err := parsePerfCounterSpecs(*perfCounterCounters)
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		perfcounterLog.Warn("No counters specified for perfcounter collector, use --collector.perfcounter.counters.")
+	}
+
+	query, err := pdh.PdhOpenQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &PerfCounterCollector{query: query}
+	for _, spec := range specs {
+		handle, err := pdh.PdhAddEnglishCounter(query, spec.path)
+		if err != nil {
+			perfcounterLog.Errorf("Failed to add counter %q for metric %q, skipping: %v", spec.path, spec.name, err)
+			continue
+		}
+		c.counters = append(c.counters, perfCounter{
+			perfCounterSpec: spec,
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(Namespace, subsystem, spec.name),
+				fmt.Sprintf("Value of the %s performance counter", spec.path),
+				[]string{"counter_instance"},
+				nil,
+			),
+			handle: handle,
+		})
+	}
+
+	// Rate counters are computed from two samples, collect a first one.
+	if err := pdh.PdhCollectQueryData(query); err != nil {
+		perfcounterLog.Debugf("Failed to collect initial perfcounter sample: %v", err)
+	}
+
+	return c, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *PerfCounterCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		perfcounterLog.Error("failed collecting perfcounter metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+func (c *PerfCounterCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	if len(c.counters) == 0 {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timestamp, err := pdh.PdhCollectQueryDataWithTime(c.query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, counter := range c.counters {
+		values, err := pdh.PdhGetFormattedCounterArrayDouble(counter.handle)
+		if err != nil {
+			// Wildcard counters without any instance, e.g. a process which
+			// isn't running, have no data.
+			perfcounterLog.Debugf("Failed to get value of counter %q: %v", counter.path, err)
+			continue
+		}
+
+		instances := make([]string, len(values))
+		for i, value := range values {
+			instances[i] = value.Instance
+		}
+		instances = uniquePerfCounterInstances(instances)
+
+		for i, value := range values {
+			if value.CStatus != pdh.PDH_CSTATUS_VALID_DATA && value.CStatus != pdh.PDH_CSTATUS_NEW_DATA {
+				continue
+			}
+			m := prometheus.MustNewConstMetric(
+				counter.desc,
+				prometheus.GaugeValue,
+				value.Value,
+				instances[i],
+			)
+			if ctx.SourceTimestamps {
+				m = prometheus.NewMetricWithTimestamp(timestamp, m)
+			}
+			ch <- m
+		}
+	}
+
+	return nil, nil
+}
+
+// uniquePerfCounterInstances makes the instance names of a wildcard counter
+// unique. PDH returns instances sharing a name, such as the many svchost
+// processes, under that same name, which perfmon shows as svchost, svchost#1,
+// svchost#2 and so on. The names are numbered the same way.
+func uniquePerfCounterInstances(instances []string) []string {
+	unique := make([]string, len(instances))
+	seen := make(map[string]bool, len(instances))
+	next := make(map[string]int)
+	for i, instance := range instances {
+		name := instance
+		for seen[name] {
+			next[instance]++
+			name = fmt.Sprintf("%s#%d", instance, next[instance])
+		}
+		seen[name] = true
+		unique[i] = name
+	}
+	return unique
+}
+
+// parsePerfCounterSpecs parses <metric name>=<counter path> counter
+// specifications. Counter paths may contain commas, e.g. in instance names,
+// so each specification is a separate entry.
+func parsePerfCounterSpecs(entries []string) ([]perfCounterSpec, error) {
+	var specs []perfCounterSpec
+	names := map[string]bool{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid perfcounter %q, expected <metric name>=<counter path>", entry)
+		}
+		name, path := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if !perfCounterMetricName.MatchString(name) {
+			return nil, fmt.Errorf("invalid perfcounter metric name %q", name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate perfcounter metric name %q", name)
+		}
+		names[name] = true
+		specs = append(specs, perfCounterSpec{name: name, path: path})
+	}
+	return specs, nil
+}
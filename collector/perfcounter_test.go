@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePerfCounterSpecs(t *testing.T) {
+	specs, err := parsePerfCounterSpecs([]string{
+		`processor_time=\Processor(*)\% Processor Time`,
+		` sql_batches=\SQLServer:SQL Statistics\Batch Requests/sec`,
+		`core_time=\Processor Information(0,1)\% Processor Time`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []perfCounterSpec{
+		{name: "processor_time", path: `\Processor(*)\% Processor Time`},
+		{name: "sql_batches", path: `\SQLServer:SQL Statistics\Batch Requests/sec`},
+		{name: "core_time", path: `\Processor Information(0,1)\% Processor Time`},
+	}
+	if !reflect.DeepEqual(specs, expected) {
+		t.Errorf("expected %v, got %v", expected, specs)
+	}
+
+	if specs, err := parsePerfCounterSpecs(nil); err != nil || len(specs) != 0 {
+		t.Errorf("expected no counters, got %v, %v", specs, err)
+	}
+
+	for _, invalid := range [][]string{
+		{`\Processor(*)\% Processor Time`},
+		{`processor_time=`},
+		{`processor-time=\Processor(*)\% Processor Time`},
+		{`a=\Memory\Available Bytes`, `a=\Memory\Committed Bytes`},
+	} {
+		if _, err := parsePerfCounterSpecs(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestUniquePerfCounterInstances(t *testing.T) {
+	instances := []string{"svchost", "System", "svchost", "svchost", "svchost#1", "_Total", ""}
+	expected := []string{"svchost", "System", "svchost#1", "svchost#2", "svchost#1#1", "_Total", ""}
+	if got := uniquePerfCounterInstances(instances); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func BenchmarkPerfCounterCollector(b *testing.B) {
+	benchmarkCollector(b, "perfcounter", NewPerfCounterCollector)
+}
@@ -0,0 +1,205 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/go-ole/go-ole"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("physical_disk", NewPhysicalDiskCollector)
+}
+
+var physicalDiskLog = log.With("collector", "physical_disk")
+
+const storageNamespace = `root\Microsoft\Windows\Storage`
+
+// A PhysicalDiskCollector is a Prometheus collector for WMI MSFT_PhysicalDisk
+// and MSFT_StorageReliabilityCounter metrics
+type PhysicalDiskCollector struct {
+	Info               *prometheus.Desc
+	HealthStatus       *prometheus.Desc
+	TemperatureCelsius *prometheus.Desc
+	WearPercent        *prometheus.Desc
+	ReadErrorsTotal    *prometheus.Desc
+	WriteErrorsTotal   *prometheus.Desc
+}
+
+// NewPhysicalDiskCollector ...
+func NewPhysicalDiskCollector() (Collector, error) {
+	const subsystem = "physical_disk"
+
+	return &PhysicalDiskCollector{
+		Info: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "info"),
+			"A metric with a constant '1' value labeled with the name and serial number of the disk",
+			[]string{"disk", "name", "serial_number"},
+			nil,
+		),
+		HealthStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "health_status"),
+			"Health of the disk: 0 healthy, 1 warning, 2 unhealthy, 5 unknown (HealthStatus)",
+			[]string{"disk"},
+			nil,
+		),
+		TemperatureCelsius: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "temperature_celsius"),
+			"Current temperature of the disk, in degrees Celsius (Temperature)",
+			[]string{"disk"},
+			nil,
+		),
+		WearPercent: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "wear_percent"),
+			"Wear of a solid state disk, in percent of its rated endurance (Wear)",
+			[]string{"disk"},
+			nil,
+		),
+		ReadErrorsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "read_errors_total"),
+			"Total number of read errors of the disk (ReadErrorsTotal)",
+			[]string{"disk"},
+			nil,
+		),
+		WriteErrorsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "write_errors_total"),
+			"Total number of write errors of the disk (WriteErrorsTotal)",
+			[]string{"disk"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *PhysicalDiskCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		physicalDiskLog.Error("failed collecting physical_disk metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// MSFT_PhysicalDisk docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/stormgmt/msft-physicaldisk
+type MSFT_PhysicalDisk struct {
+	DeviceId     string
+	FriendlyName string
+	SerialNumber string
+	HealthStatus uint16
+}
+
+// MSFT_StorageReliabilityCounter docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/stormgmt/msft-storagereliabilitycounter
+//
+// The counters are only reported by some disks, and are NULL otherwise.
+type MSFT_StorageReliabilityCounter struct {
+	DeviceId         string
+	Temperature      *uint64
+	Wear             *uint64
+	ReadErrorsTotal  *uint64
+	WriteErrorsTotal *uint64
+}
+
+// queryStorageReliabilityCounters reads the reliability counters of the
+// disks. The wmi package allocates pointer fields before reading the
+// properties, leaving them at zero rather than nil when they are NULL, so
+// this goes through the scripting API to tell missing counters apart.
+func queryStorageReliabilityCounters() ([]MSFT_StorageReliabilityCounter, error) {
+	var counters []MSFT_StorageReliabilityCounter
+	err := oleQueryNamespace(storageNamespace, "SELECT DeviceId, Temperature, Wear, ReadErrorsTotal, WriteErrorsTotal FROM MSFT_StorageReliabilityCounter", func(item *ole.IDispatch) error {
+		var (
+			counter MSFT_StorageReliabilityCounter
+			err     error
+		)
+		if counter.DeviceId, err = oleString(item, "DeviceId"); err != nil {
+			return err
+		}
+		for property, dst := range map[string]**uint64{
+			"Temperature":      &counter.Temperature,
+			"Wear":             &counter.Wear,
+			"ReadErrorsTotal":  &counter.ReadErrorsTotal,
+			"WriteErrorsTotal": &counter.WriteErrorsTotal,
+		} {
+			if *dst, err = oleNullableUint64(item, property); err != nil {
+				return err
+			}
+		}
+		counters = append(counters, counter)
+		return nil
+	})
+	return counters, err
+}
+
+func (c *PhysicalDiskCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var disks []MSFT_PhysicalDisk
+	q := queryAll(&disks)
+	if err := wmiQueryNamespace(q, &disks, storageNamespace); err != nil {
+		if isWMINotFoundError(err) {
+			physicalDiskLog.Debugf("Storage WMI namespace not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for _, disk := range disks {
+		ch <- prometheus.MustNewConstMetric(
+			c.Info,
+			prometheus.GaugeValue,
+			1.0,
+			disk.DeviceId,
+			disk.FriendlyName,
+			disk.SerialNumber,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.HealthStatus,
+			prometheus.GaugeValue,
+			float64(disk.HealthStatus),
+			disk.DeviceId,
+		)
+	}
+
+	counters, err := queryStorageReliabilityCounters()
+	if err != nil {
+		return c.TemperatureCelsius, err
+	}
+
+	for _, counter := range counters {
+		// A temperature of 0 means that the disk doesn't report it.
+		if counter.Temperature != nil && *counter.Temperature > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.TemperatureCelsius,
+				prometheus.GaugeValue,
+				float64(*counter.Temperature),
+				counter.DeviceId,
+			)
+		}
+		if counter.Wear != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.WearPercent,
+				prometheus.GaugeValue,
+				float64(*counter.Wear),
+				counter.DeviceId,
+			)
+		}
+		if counter.ReadErrorsTotal != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.ReadErrorsTotal,
+				prometheus.CounterValue,
+				float64(*counter.ReadErrorsTotal),
+				counter.DeviceId,
+			)
+		}
+		if counter.WriteErrorsTotal != nil {
+			ch <- prometheus.MustNewConstMetric(
+				c.WriteErrorsTotal,
+				prometheus.CounterValue,
+				float64(*counter.WriteErrorsTotal),
+				counter.DeviceId,
+			)
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkPhysicalDiskCollector(b *testing.B) {
+	benchmarkCollector(b, "physical_disk", NewPhysicalDiskCollector)
+}
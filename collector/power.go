@@ -0,0 +1,80 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("power", newPowerCollector, "Energy Meter")
+}
+
+var powerLog = log.With("collector", "power")
+
+// A PowerCollector is a Prometheus collector for Perflib Energy Meter metrics
+type PowerCollector struct {
+	ConsumptionWatts *prometheus.Desc
+}
+
+func newPowerCollector() (Collector, error) {
+	const subsystem = "power"
+
+	return &PowerCollector{
+		ConsumptionWatts: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "consumption_watts"),
+			"Power currently measured by the energy meter, in watts (Energy Meter.Power)",
+			[]string{"meter"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *PowerCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		powerLog.Error("failed collecting power metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib "Energy Meter"
+type energyMeter struct {
+	Name string
+
+	Power float64 `perflib:"Power"`
+}
+
+func (c *PowerCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	obj, ok := ctx.perfObjects["Energy Meter"]
+	if !ok {
+		// Only exposed on hardware with an Energy Metering Interface.
+		powerLog.Debug("Energy Meter counters are not available. Skipping")
+		return nil, nil
+	}
+
+	var dst []energyMeter
+	if err := unmarshalObject(obj, &dst); err != nil {
+		powerLog.Debugf("Could not read Energy Meter counters: %v. Skipping", err)
+		return nil, nil
+	}
+
+	for _, meter := range dst {
+		if meter.Name == "_Total" {
+			continue
+		}
+
+		// Power is reported in milliwatts.
+		ch <- prometheus.MustNewConstMetric(
+			c.ConsumptionWatts,
+			prometheus.GaugeValue,
+			meter.Power/1000,
+			meter.Name,
+		)
+	}
+
+	return nil, nil
+}
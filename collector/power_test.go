@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkPowerCollector(b *testing.B) {
+	benchmarkCollector(b, "power", newPowerCollector)
+}
@@ -0,0 +1,177 @@
+// +build windows
+
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func init() {
+	registerCollector("printer", NewPrinterCollector)
+}
+
+var printerLog = log.With("collector", "printer")
+
+var (
+	printerWhereClause = kingpin.Flag(
+		"collector.printer.printers-where",
+		"WQL 'where' clause to use in WMI metrics query. Limits the response to the printers you specify and reduces the size of the response.",
+	).Default("").String()
+	printerExcludeVirtual = kingpin.Flag(
+		"collector.printer.exclude-virtual",
+		"Exclude virtual printers, such as Microsoft Print to PDF, which don't print to a device.",
+	).Default("false").Bool()
+)
+
+// A PrinterCollector is a Prometheus collector for WMI Win32_Printer and Win32_PerfFormattedData_Spooler_PrintQueue metrics
+type PrinterCollector struct {
+	Status         *prometheus.Desc
+	Jobs           *prometheus.Desc
+	JobErrorsTotal *prometheus.Desc
+
+	queryWhereClause string
+	excludeVirtual   bool
+}
+
+// NewPrinterCollector ...
+func NewPrinterCollector() (Collector, error) {
+	const subsystem = "printer"
+
+	return &PrinterCollector{
+		Status: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "status"),
+			"The status of the printer, 1 if the current status, 0 otherwise (PrinterStatus)",
+			[]string{"printer", "status"},
+			nil,
+		),
+		Jobs: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "jobs"),
+			"Number of jobs in the print queue (PrintQueue.Jobs)",
+			[]string{"printer"},
+			nil,
+		),
+		JobErrorsTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "job_errors_total"),
+			"Number of job errors in the print queue since the last restart of the spooler (PrintQueue.JobErrors)",
+			[]string{"printer"},
+			nil,
+		),
+		queryWhereClause: *printerWhereClause,
+		excludeVirtual:   *printerExcludeVirtual,
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *PrinterCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		printerLog.Error("failed collecting printer metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Win32_Printer docs:
+// - https://docs.microsoft.com/en-us/windows/win32/cimwin32prov/win32-printer
+type Win32_Printer struct {
+	Name          string
+	PortName      string
+	PrinterStatus uint16
+}
+
+// Win32_PerfFormattedData_Spooler_PrintQueue docs:
+// - https://wutils.com/wmi/root/cimv2/win32_perfformatteddata_spooler_printqueue/
+type Win32_PerfFormattedData_Spooler_PrintQueue struct {
+	Name      string
+	Jobs      uint32
+	JobErrors uint32
+}
+
+var (
+	allPrinterStatuses = []string{
+		"other",
+		"unknown",
+		"idle",
+		"printing",
+		"warmup",
+		"stopped printing",
+		"offline",
+	}
+)
+
+// isVirtualPrinter reports whether the printer doesn't print to a device,
+// based on the port it is attached to.
+func isVirtualPrinter(portName string) bool {
+	switch strings.ToUpper(portName) {
+	case "PORTPROMPT:", "NUL:", "SHRFAX:", "XPSPORT:", "FILE:":
+		return true
+	}
+	return false
+}
+
+func (c *PrinterCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var printers []Win32_Printer
+	q := queryAllWhere(&printers, c.queryWhereClause)
+	if err := wmiQuery(q, &printers); err != nil {
+		return nil, err
+	}
+
+	included := make(map[string]bool, len(printers))
+	for _, printer := range printers {
+		if c.excludeVirtual && isVirtualPrinter(printer.PortName) {
+			continue
+		}
+		included[printer.Name] = true
+
+		// PrinterStatus values start at 1 (Other)
+		currentStatus := ""
+		if printer.PrinterStatus >= 1 && int(printer.PrinterStatus) <= len(allPrinterStatuses) {
+			currentStatus = allPrinterStatuses[printer.PrinterStatus-1]
+		}
+		for _, status := range allPrinterStatuses {
+			isCurrentStatus := 0.0
+			if status == currentStatus {
+				isCurrentStatus = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.Status,
+				prometheus.GaugeValue,
+				isCurrentStatus,
+				printer.Name,
+				status,
+			)
+		}
+	}
+
+	var queues []Win32_PerfFormattedData_Spooler_PrintQueue
+	q = queryAll(&queues)
+	if err := wmiQuery(q, &queues); err != nil {
+		return nil, err
+	}
+
+	for _, queue := range queues {
+		if !included[queue.Name] {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.Jobs,
+			prometheus.GaugeValue,
+			float64(queue.Jobs),
+			queue.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.JobErrorsTotal,
+			prometheus.CounterValue,
+			float64(queue.JobErrors),
+			queue.Name,
+		)
+	}
+
+	return nil, nil
+}
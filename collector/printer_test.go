@@ -0,0 +1,25 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestIsVirtualPrinter(t *testing.T) {
+	cases := map[string]bool{
+		"PORTPROMPT:":   true,
+		"nul:":          true,
+		"SHRFAX:":       true,
+		"USB001":        false,
+		"IP_10.0.0.12":  false,
+		`\\srv\printer`: false,
+	}
+	for portName, expected := range cases {
+		if got := isVirtualPrinter(portName); got != expected {
+			t.Errorf("isVirtualPrinter(%q): expected %v, got %v", portName, expected, got)
+		}
+	}
+}
+
+func BenchmarkPrinterCollector(b *testing.B) {
+	benchmarkCollector(b, "printer", NewPrinterCollector)
+}
@@ -8,9 +8,10 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/StackExchange/wmi"
+	"github.com/prometheus-community/windows_exporter/headers/jobapi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
 
@@ -18,6 +19,8 @@ func init() {
 	registerCollector("process", newProcessCollector, "Process")
 }
 
+var processLog = log.With("collector", "process")
+
 var (
 	processWhitelist = kingpin.Flag(
 		"collector.process.whitelist",
@@ -27,6 +30,22 @@ var (
 		"collector.process.blacklist",
 		"Regexp of processes to exclude. Process name must both match whitelist and not match blacklist to be included.",
 	).Default("").String()
+	processDetail = kingpin.Flag(
+		"collector.process.detail",
+		"Expose additional per-process metrics, which require opening a handle to each process.",
+	).Default("false").Bool()
+	processParentInfo = kingpin.Flag(
+		"collector.process.parent-info",
+		"Expose windows_process_parent_info, labeled with the ID and name of the parent of each process.",
+	).Default("false").Bool()
+	processServices = kingpin.Flag(
+		"collector.process.services",
+		"Expose windows_process_service, mapping the ID of each process to the services it hosts.",
+	).Default("false").Bool()
+	processJobObjects = kingpin.Flag(
+		"collector.process.job-objects",
+		"Comma-separated list of named job objects to identify in the job label of windows_process_job_object. Requires collector.process.detail.",
+	).Default("").String()
 )
 
 type processCollector struct {
@@ -45,9 +64,17 @@ type processCollector struct {
 	WorkingSetPrivate *prometheus.Desc
 	WorkingSetPeak    *prometheus.Desc
 	WorkingSet        *prometheus.Desc
+	JobObject         *prometheus.Desc
+	ParentInfo        *prometheus.Desc
+	Service           *prometheus.Desc
 
 	processWhitelistPattern *regexp.Regexp
 	processBlacklistPattern *regexp.Regexp
+
+	detail     bool
+	parentInfo bool
+	services   bool
+	jobObjects []string
 }
 
 // NewProcessCollector ...
@@ -55,7 +82,7 @@ func newProcessCollector() (Collector, error) {
 	const subsystem = "process"
 
 	if *processWhitelist == ".*" && *processBlacklist == "" {
-		log.Warn("No filters specified for process collector. This will generate a very large number of metrics!")
+		processLog.Warn("No filters specified for process collector. This will generate a very large number of metrics!")
 	}
 
 	return &processCollector{
@@ -149,8 +176,30 @@ func newProcessCollector() (Collector, error) {
 			[]string{"process", "process_id", "creating_process_id"},
 			nil,
 		),
+		JobObject: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "job_object"),
+			"A metric with a constant '1' value for processes running in a job object, labeled with the name of the job object if known.",
+			[]string{"process", "process_id", "job"},
+			nil,
+		),
+		ParentInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "parent_info"),
+			"A metric with a constant '1' value labeled with the ID and name of the parent process. The name is empty if the parent has exited.",
+			[]string{"process", "process_id", "parent_process_id", "parent_name"},
+			nil,
+		),
+		Service: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "service"),
+			"A metric with a constant '1' value for each service hosted by the process.",
+			[]string{"process", "process_id", "service"},
+			nil,
+		),
 		processWhitelistPattern: regexp.MustCompile(fmt.Sprintf("^(?:%s)$", *processWhitelist)),
 		processBlacklistPattern: regexp.MustCompile(fmt.Sprintf("^(?:%s)$", *processBlacklist)),
+		detail:                  *processDetail,
+		parentInfo:              *processParentInfo,
+		services:                *processServices,
+		jobObjects:              strings.FieldsFunc(*processJobObjects, func(r rune) bool { return r == ',' }),
 	}, nil
 }
 
@@ -200,8 +249,32 @@ func (c *processCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metr
 
 	var dst_wp []WorkerProcess
 	q_wp := queryAll(&dst_wp)
-	if err := wmi.QueryNamespace(q_wp, &dst_wp, "root\\WebAdministration"); err != nil {
-		log.Debugf("Could not query WebAdministration namespace for IIS worker processes: %v. Skipping", err)
+	if err := wmiQueryNamespace(q_wp, &dst_wp, "root\\WebAdministration"); err != nil {
+		processLog.Debugf("Could not query WebAdministration namespace for IIS worker processes: %v. Skipping", err)
+	}
+
+	var parents map[float64]processIdentity
+	if c.parentInfo {
+		parents = make(map[float64]processIdentity, len(data))
+		for _, process := range data {
+			parents[process.IDProcess] = processIdentity{
+				name:      strings.Split(process.Name, "#")[0],
+				startTime: process.ElapsedTime,
+			}
+		}
+	}
+
+	var services map[uint32][]string
+	if c.services {
+		if services, err = serviceProcessIDs(); err != nil {
+			processLog.Warnf("Could not enumerate services: %v", err)
+		}
+	}
+
+	var jobs map[string]windows.Handle
+	if c.detail {
+		jobs = openJobObjects(c.jobObjects)
+		defer closeJobObjects(jobs)
 	}
 
 	for _, process := range data {
@@ -420,7 +493,125 @@ func (c *processCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metr
 			pid,
 			cpid,
 		)
+
+		if c.parentInfo {
+			ch <- prometheus.MustNewConstMetric(
+				c.ParentInfo,
+				prometheus.GaugeValue,
+				1.0,
+				processName,
+				pid,
+				cpid,
+				parentProcessName(parents, process.CreatingProcessID, process.ElapsedTime),
+			)
+		}
+
+		for _, service := range services[uint32(process.IDProcess)] {
+			ch <- prometheus.MustNewConstMetric(
+				c.Service,
+				prometheus.GaugeValue,
+				1.0,
+				processName,
+				pid,
+				service,
+			)
+		}
+
+		if c.detail {
+			c.collectDetail(ch, uint32(process.IDProcess), processName, pid, jobs)
+		}
 	}
 
 	return nil
 }
+
+// processIdentity identifies a process, as PIDs are reused by Windows.
+type processIdentity struct {
+	name      string
+	startTime float64
+}
+
+// parentProcessName returns the name of the parent of a process started at
+// startTime, or an empty string if the parent has exited. A parent started
+// after its child is a newer process which reused the PID of the parent.
+func parentProcessName(processes map[float64]processIdentity, parentPID float64, startTime float64) string {
+	parent, ok := processes[parentPID]
+	if !ok || parent.startTime > startTime {
+		return ""
+	}
+	return parent.name
+}
+
+// collectDetail exposes the metrics which require opening a handle to the
+// process. Processes which can't be opened, e.g. protected processes, are skipped.
+func (c *processCollector) collectDetail(ch chan<- prometheus.Metric, processID uint32, processName string, pid string, jobs map[string]windows.Handle) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, processID)
+	if err != nil {
+		processLog.Debugf("Could not open process %s (%s): %v. Skipping", processName, pid, err)
+		return
+	}
+	defer windows.CloseHandle(handle)
+
+	inJob, err := jobapi.IsProcessInJob(handle, 0)
+	if err != nil {
+		processLog.Debugf("Could not determine job object of process %s (%s): %v", processName, pid, err)
+	} else if inJob {
+		jobName := ""
+		for name, job := range jobs {
+			if inNamedJob, err := jobapi.IsProcessInJob(handle, job); err == nil && inNamedJob {
+				jobName = name
+				break
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.JobObject,
+			prometheus.GaugeValue,
+			1.0,
+			processName,
+			pid,
+			jobName,
+		)
+	}
+}
+
+// serviceProcessIDs returns the names of the running services, by ID of the
+// process hosting them. A process can host several services, e.g. svchost.
+func serviceProcessIDs() (map[uint32][]string, error) {
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_ENUMERATE_SERVICE)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseServiceHandle(scm)
+
+	entries, err := enumServices(scm, windows.SERVICE_ACTIVE)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make(map[uint32][]string, len(entries))
+	for _, entry := range entries {
+		pid := entry.status.ProcessId
+		services[pid] = append(services[pid], strings.ToLower(entry.name))
+	}
+	return services, nil
+}
+
+// openJobObjects opens the named job objects which exist on the system.
+func openJobObjects(names []string) map[string]windows.Handle {
+	jobs := make(map[string]windows.Handle, len(names))
+	for _, name := range names {
+		job, err := jobapi.OpenJobObject(jobapi.JOB_OBJECT_QUERY, false, name)
+		if err != nil {
+			processLog.Debugf("Could not open job object %q: %v", name, err)
+			continue
+		}
+		jobs[name] = job
+	}
+	return jobs
+}
+
+func closeJobObjects(jobs map[string]windows.Handle) {
+	for _, job := range jobs {
+		_ = windows.CloseHandle(job)
+	}
+}
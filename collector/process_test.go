@@ -4,6 +4,30 @@ import (
 	"testing"
 )
 
+func TestParentProcessName(t *testing.T) {
+	processes := map[float64]processIdentity{
+		4:    {name: "System", startTime: 1000},
+		1234: {name: "WINWORD", startTime: 2000},
+	}
+
+	cases := []struct {
+		parentPID float64
+		startTime float64
+		expected  string
+	}{
+		{1234, 2500, "WINWORD"},
+		// The parent exited.
+		{5678, 2500, ""},
+		// The PID of the parent was reused by a newer process.
+		{1234, 1500, ""},
+	}
+	for _, c := range cases {
+		if got := parentProcessName(processes, c.parentPID, c.startTime); got != c.expected {
+			t.Errorf("parent %v of process started at %v: expected %q, got %q", c.parentPID, c.startTime, c.expected, got)
+		}
+	}
+}
+
 func BenchmarkProcessCollector(b *testing.B) {
 	// Whitelist is not set in testing context (kingpin flags not parsed), causing the collector to skip all processes.
 	localProcessWhitelist := ".+"
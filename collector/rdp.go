@@ -0,0 +1,169 @@
+// +build windows
+
+package collector
+
+import (
+	"strconv"
+
+	"github.com/prometheus-community/windows_exporter/headers/wtsapi32"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func init() {
+	registerCollector("rdp", newRDPCollector)
+}
+
+var rdpLog = log.With("collector", "rdp")
+
+var rdpIncludeUserLabels = kingpin.Flag(
+	"collector.rdp.include-user-labels",
+	"Add a user label to the per session metrics.",
+).Default("false").Bool()
+
+// rdpServicesSessionID is the ID of the session the services run in, which
+// users can't log on to.
+const rdpServicesSessionID = 0
+
+// rdpSessionStates maps WTS_CONNECTSTATE_CLASS values to label values
+var rdpSessionStates = map[uint32]string{
+	windows.WTSActive:       "active",
+	windows.WTSConnected:    "connected",
+	windows.WTSConnectQuery: "connect_query",
+	windows.WTSShadow:       "shadow",
+	windows.WTSDisconnected: "disconnected",
+	windows.WTSIdle:         "idle",
+	windows.WTSListen:       "listen",
+	windows.WTSReset:        "reset",
+	windows.WTSDown:         "down",
+	windows.WTSInit:         "init",
+}
+
+// A RDPCollector is a Prometheus collector for the Remote Desktop Services
+// sessions, queried through the WTS API
+type RDPCollector struct {
+	Sessions           *prometheus.Desc
+	SessionIdleSeconds *prometheus.Desc
+
+	includeUserLabels bool
+}
+
+func newRDPCollector() (Collector, error) {
+	const subsystem = "rdp"
+
+	sessionLabels := []string{"session_id"}
+	if *rdpIncludeUserLabels {
+		sessionLabels = append(sessionLabels, "user")
+	}
+
+	return &RDPCollector{
+		Sessions: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "sessions"),
+			"Number of sessions, by state. The services session is not counted",
+			[]string{"state"},
+			nil,
+		),
+		SessionIdleSeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "session_idle_seconds"),
+			"Time since the last input in a user session",
+			sessionLabels,
+			nil,
+		),
+		includeUserLabels: *rdpIncludeUserLabels,
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *RDPCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		rdpLog.Error("failed collecting rdp metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+func (c *RDPCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	sessions, err := wtsapi32.EnumerateSessions()
+	if err != nil {
+		return c.Sessions, err
+	}
+
+	counts := make(map[string]float64, len(rdpSessionStates))
+	for _, state := range rdpSessionStates {
+		counts[state] = 0
+	}
+
+	for _, session := range sessions {
+		if session.ID == rdpServicesSessionID {
+			continue
+		}
+		state, ok := rdpSessionStates[session.State]
+		if !ok {
+			rdpLog.Debugf("Unknown state %d of session %d", session.State, session.ID)
+			continue
+		}
+		counts[state]++
+
+		// Listeners wait for incoming connections, and have no user.
+		if session.State == windows.WTSListen {
+			continue
+		}
+		info, err := wtsapi32.QuerySessionInfo(session.ID)
+		if err != nil {
+			// The session may have ended since the enumeration.
+			rdpLog.Debugf("Couldn't query session %d: %v", session.ID, err)
+			continue
+		}
+		if info.UserName == "" {
+			continue
+		}
+		idle, ok := rdpSessionIdleSeconds(info)
+		if !ok {
+			continue
+		}
+
+		labels := []string{strconv.FormatUint(uint64(session.ID), 10)}
+		if c.includeUserLabels {
+			user := info.UserName
+			if info.Domain != "" {
+				user = info.Domain + `\` + user
+			}
+			labels = append(labels, user)
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.SessionIdleSeconds,
+			prometheus.GaugeValue,
+			idle,
+			labels...,
+		)
+	}
+
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.Sessions,
+			prometheus.GaugeValue,
+			count,
+			state,
+		)
+	}
+
+	return nil, nil
+}
+
+// rdpSessionIdleSeconds returns the time since the last input in a session.
+// Sessions which didn't receive any input since they were disconnected are
+// idle since the disconnection.
+func rdpSessionIdleSeconds(info wtsapi32.SessionInfo) (float64, bool) {
+	since := info.LastInputTime
+	if since == 0 {
+		since = info.DisconnectTime
+	}
+	if since == 0 || info.CurrentTime < since {
+		return 0, false
+	}
+	// FILETIMEs are in 100 nanoseconds intervals.
+	return float64(info.CurrentTime-since) / 1e7, true
+}
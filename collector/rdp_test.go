@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/windows_exporter/headers/wtsapi32"
+)
+
+func TestRDPSessionIdleSeconds(t *testing.T) {
+	cases := []struct {
+		info wtsapi32.SessionInfo
+		idle float64
+		ok   bool
+	}{
+		{wtsapi32.SessionInfo{LastInputTime: 100e7, CurrentTime: 160e7}, 60, true},
+		{wtsapi32.SessionInfo{DisconnectTime: 100e7, CurrentTime: 130e7}, 30, true},
+		{wtsapi32.SessionInfo{LastInputTime: 120e7, DisconnectTime: 100e7, CurrentTime: 130e7}, 10, true},
+		{wtsapi32.SessionInfo{CurrentTime: 130e7}, 0, false},
+		{wtsapi32.SessionInfo{LastInputTime: 140e7, CurrentTime: 130e7}, 0, false},
+	}
+	for _, tc := range cases {
+		idle, ok := rdpSessionIdleSeconds(tc.info)
+		if idle != tc.idle || ok != tc.ok {
+			t.Errorf("rdpSessionIdleSeconds(%+v) = %v, %t, want %v, %t", tc.info, idle, ok, tc.idle, tc.ok)
+		}
+	}
+}
+
+func BenchmarkRDPCollector(b *testing.B) {
+	benchmarkCollector(b, "rdp", newRDPCollector)
+}
@@ -0,0 +1,113 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/headers/iphlpapi"
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+func init() {
+	registerCollector("route", newRouteCollector)
+}
+
+var routeLog = log.With("collector", "route")
+
+// A RouteCollector is a Prometheus collector for the IPv4 and IPv6 route
+// tables, queried with GetIpForwardTable2. Only default routes are exposed in
+// detail, to keep the cardinality low.
+type RouteCollector struct {
+	Routes             *prometheus.Desc
+	DefaultGatewayInfo *prometheus.Desc
+}
+
+func newRouteCollector() (Collector, error) {
+	const subsystem = "route"
+
+	return &RouteCollector{
+		Routes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "routes"),
+			"Number of routes in the route table",
+			[]string{"af"},
+			nil,
+		),
+		DefaultGatewayInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "default_gateway_info"),
+			"A metric with a constant '1' value labeled with the gateway and interface of each default route",
+			[]string{"af", "gateway", "interface"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *RouteCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		routeLog.Error("failed collecting route metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// routeAddressFamilies maps address families to label values
+var routeAddressFamilies = map[uint16]string{
+	windows.AF_INET:  "ipv4",
+	windows.AF_INET6: "ipv6",
+}
+
+func (c *RouteCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	routes, err := iphlpapi.GetIPForwardTable2(windows.AF_UNSPEC)
+	if err != nil {
+		return c.Routes, err
+	}
+	interfaces, err := iphlpapi.GetIfTable2()
+	if err != nil {
+		return c.DefaultGatewayInfo, err
+	}
+	aliases := make(map[uint32]string, len(interfaces))
+	for _, i := range interfaces {
+		aliases[i.InterfaceIndex] = i.AliasString()
+	}
+
+	counts := make(map[string]float64, len(routeAddressFamilies))
+	for _, af := range routeAddressFamilies {
+		counts[af] = 0
+	}
+	for _, route := range routes {
+		af, ok := routeAddressFamilies[route.DestinationPrefix.Prefix.Family()]
+		if !ok {
+			continue
+		}
+		counts[af]++
+
+		if route.DestinationPrefix.PrefixLength != 0 {
+			continue
+		}
+		gateway := ""
+		if ip := route.NextHop.IP(); ip != nil {
+			gateway = ip.String()
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.DefaultGatewayInfo,
+			prometheus.GaugeValue,
+			1.0,
+			af,
+			gateway,
+			aliases[route.InterfaceIndex],
+		)
+	}
+
+	for af, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.Routes,
+			prometheus.GaugeValue,
+			count,
+			af,
+		)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkRouteCollector(b *testing.B) {
+	benchmarkCollector(b, "route", newRouteCollector)
+}
@@ -0,0 +1,143 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("search", newSearchCollector, "Search Indexer", "Search Gatherer Projects")
+}
+
+var searchLog = log.With("collector", "search")
+
+// A searchCollector is a Prometheus collector for the Windows Search indexer
+// perflib metrics
+type searchCollector struct {
+	IndexedItems       *prometheus.Desc
+	PendingItems       *prometheus.Desc
+	QueriesTotal       *prometheus.Desc
+	QueriesFailedTotal *prometheus.Desc
+}
+
+func newSearchCollector() (Collector, error) {
+	const subsystem = "search"
+	return &searchCollector{
+		IndexedItems: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "indexed_items"),
+			"Number of items in the index (Search Indexer.Index Size)",
+			[]string{"indexer"},
+			nil,
+		),
+		PendingItems: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "pending_items"),
+			"Number of items waiting to be indexed (Search Gatherer Projects.Waiting Documents)",
+			[]string{"indexer"},
+			nil,
+		),
+		QueriesTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "queries_total"),
+			"Number of queries run against the index (Search Indexer.Queries)",
+			[]string{"indexer"},
+			nil,
+		),
+		QueriesFailedTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "queries_failed_total"),
+			"Number of queries against the index that failed (Search Indexer.Queries Failed)",
+			[]string{"indexer"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *searchCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		searchLog.Error("failed collecting search metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib: "Search Indexer"
+type searchIndexer struct {
+	Name string
+
+	IndexSize     float64 `perflib:"Index Size"`
+	Queries       float64 `perflib:"Queries"`
+	QueriesFailed float64 `perflib:"Queries Failed"`
+}
+
+// Perflib: "Search Gatherer Projects"
+type searchGathererProject struct {
+	Name string
+
+	WaitingDocuments float64 `perflib:"Waiting Documents"`
+}
+
+func (c *searchCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	// The counters are provided by the indexer itself, so they are missing
+	// when the Windows Search service isn't running
+	if ctx.perfObjects["Search Indexer"] == nil {
+		searchLog.Debug("Search Indexer performance counters not found, the Windows Search service is probably not running. Skipping")
+		return nil, nil
+	}
+
+	var indexers []searchIndexer
+	if err := unmarshalObject(ctx.perfObjects["Search Indexer"], &indexers); err != nil {
+		return nil, err
+	}
+
+	for _, indexer := range indexers {
+		if indexer.Name == "_Total" {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.IndexedItems,
+			prometheus.GaugeValue,
+			indexer.IndexSize,
+			indexer.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.QueriesTotal,
+			prometheus.CounterValue,
+			indexer.Queries,
+			indexer.Name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.QueriesFailedTotal,
+			prometheus.CounterValue,
+			indexer.QueriesFailed,
+			indexer.Name,
+		)
+	}
+
+	if ctx.perfObjects["Search Gatherer Projects"] == nil {
+		searchLog.Debug("Search Gatherer Projects performance counters not found. Skipping pending items")
+		return nil, nil
+	}
+
+	var projects []searchGathererProject
+	if err := unmarshalObject(ctx.perfObjects["Search Gatherer Projects"], &projects); err != nil {
+		return nil, err
+	}
+
+	for _, project := range projects {
+		if project.Name == "_Total" {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.PendingItems,
+			prometheus.GaugeValue,
+			project.WaitingDocuments,
+			project.Name,
+		)
+	}
+
+	return nil, nil
+}
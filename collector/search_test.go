@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkSearchCollector(b *testing.B) {
+	benchmarkCollector(b, "search", newSearchCollector)
+}
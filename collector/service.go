@@ -3,12 +3,22 @@
 package collector
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unsafe"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 	"golang.org/x/sys/windows"
 	"golang.org/x/sys/windows/svc/mgr"
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -18,94 +28,928 @@ func init() {
 	registerCollector("service", NewserviceCollector)
 }
 
+var serviceLog = log.With("collector", "service")
+
 var (
 	serviceWhereClause = kingpin.Flag(
 		"collector.service.services-where",
 		"WQL 'where' clause to use in WMI metrics query. Limits the response to the services you specify and reduces the size of the response.",
-	).Default("").String()
+	).Default("").Envar("WINDOWS_EXPORTER_COLLECTOR_SERVICE_SERVICES_WHERE").String()
 	useAPI = kingpin.Flag(
 		"collector.service.use-api",
-		"Use API calls to collect service data instead of WMI. Flag 'collector.service.services-where' won't be effective.",
+		"DEPRECATED: use 'collector.service.query-mode=api' instead. Use API calls to collect service data instead of WMI.",
+	).Default("false").Bool()
+	serviceQueryModeFlag = kingpin.Flag(
+		"collector.service.query-mode",
+		"How to collect service data, 'wmi' (default), 'api', or 'both' to compare the two while migrating. Flag 'collector.service.services-where' won't be effective in 'api' mode, use 'collector.service.include' and 'collector.service.exclude' instead. Takes precedence over 'collector.service.use-api'.",
+	).Enum(serviceQueryModeWMI, serviceQueryModeAPI, serviceQueryModeBoth)
+
+	serviceRunningOnlyMetric = kingpin.Flag(
+		"collector.service.running-only-metric",
+		"Also expose windows_service_up, a single series per service which is 1 if the service is running.",
 	).Default("false").Bool()
+	serviceDisplayNameMetric = kingpin.Flag(
+		"collector.service.display-name-metric",
+		"Also expose windows_service_display_info, linking the name of each service to its display name, to join on display names.",
+	).Default("false").Bool()
+	serviceRunAs = kingpin.Flag(
+		"collector.service.run-as",
+		"Regexp of the account services run as. When set, only services whose account matches are included.",
+	).Default("").String()
+	serviceInclude = kingpin.Flag(
+		"collector.service.include",
+		"Regexp of service names to include, matched case-insensitively. Service name must both match include and not match exclude to be included.",
+	).Default(".+").String()
+	serviceExclude = kingpin.Flag(
+		"collector.service.exclude",
+		"Regexp of service names to exclude, matched case-insensitively. Service name must both match include and not match exclude to be included.",
+	).Default("").String()
+	serviceConfigRefreshInterval = kingpin.Flag(
+		"collector.service.config-refresh-interval",
+		"Number of scrapes between refreshes of the configuration of each service (API mode only). The status is queried on every scrape.",
+	).Default("1").Int()
+
+	serviceIncludeFile = kingpin.Flag(
+		"collector.service.include-file",
+		"File listing the names of the services to include, one per line. Empty or missing means all services.",
+	).Default("").String()
+	serviceWatchIncludeFile = kingpin.Flag(
+		"collector.service.watch-include-file",
+		"Re-read collector.service.include-file when it changes.",
+	).Default("false").Bool()
+	serviceInfoProcessIDLabel = kingpin.Flag(
+		"collector.service.info-process-id-label",
+		"DEPRECATED: Keep the process_id label on windows_service_info. The process ID is exposed by windows_service_process_id.",
+	).Default("false").Bool()
+	serviceSanitizeNames = kingpin.Flag(
+		"collector.service.sanitize-names",
+		"Add a sanitized_name label to windows_service_info, the service name with any character other than letters, digits and underscores replaced by an underscore.",
+	).Default("false").Bool()
+	serviceExtraLabels = kingpin.Flag(
+		"collector.service.extra-labels",
+		"Static label added to every series of the service collector, as key=value. May be repeated.",
+	).Strings()
+
+	useAPIDeprecationOnce sync.Once
+)
+
+const (
+	serviceQueryModeWMI = "wmi"
+	serviceQueryModeAPI = "api"
+	// serviceQueryModeBoth exposes the WMI metrics, and compares them with
+	// the API results to validate a migration.
+	serviceQueryModeBoth = "both"
 )
 
 // A serviceCollector is a Prometheus collector for WMI Win32_Service metrics
 type serviceCollector struct {
 	Information *prometheus.Desc
+	Account     *prometheus.Desc
+	ProcessID   *prometheus.Desc
 	State       *prometheus.Desc
 	StartMode   *prometheus.Desc
 	Status      *prometheus.Desc
 
+	Up               *prometheus.Desc
+	DisplayInfo      *prometheus.Desc
+	StateTransitions *prometheus.Desc
+	WaitHint         *prometheus.Desc
+	CheckPoint       *prometheus.Desc
+	PendingScrapes   *prometheus.Desc
+
+	FailureCommandConfigured *prometheus.Desc
+	FailureRebootConfigured  *prometheus.Desc
+
+	Win32ExitCode           *prometheus.Desc
+	ServiceSpecificExitCode *prometheus.Desc
+
+	ModeMismatch    *prometheus.Desc
+	AccessDenied    *prometheus.Desc
+	Count           *prometheus.Desc
+	CollectErrors   *prometheus.Desc
+	StateUnexpected *prometheus.Desc
+
+	queryMode        string
 	queryWhereClause string
+	includeList      *serviceIncludeList
+	includePattern   *regexp.Regexp
+	excludePattern   *regexp.Regexp
+	runAsPattern     *regexp.Regexp
+	upMetric         bool
+	displayMetric    bool
+	sanitizeNames    bool
+	processIDLabel   bool
+	hostname         string
+
+	accessDeniedOnce sync.Once
+
+	transitions   *serviceTransitionTracker
+	pending       *servicePendingTracker
+	configs       *serviceConfigCache
+	collectErrors *serviceCollectErrors
 }
 
 // NewserviceCollector ...
 func NewserviceCollector() (Collector, error) {
 	const subsystem = "service"
 
-	if *serviceWhereClause == "" {
-		log.Warn("No where-clause specified for service collector. This will generate a very large number of metrics!")
-	}
 	if *useAPI {
-		log.Warn("API collection is enabled.")
+		useAPIDeprecationOnce.Do(func() {
+			serviceLog.Warn("Flag 'collector.service.use-api' is deprecated, use 'collector.service.query-mode=api' instead.")
+		})
+	}
+	queryMode := serviceQueryMode(*serviceQueryModeFlag, *useAPI)
+
+	extraLabels, err := parseServiceExtraLabels(*serviceExtraLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	includeList, err := newServiceIncludeList(*serviceIncludeFile, *serviceWatchIncludeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	includePattern, err := compileServiceNamePattern(*serviceInclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collector.service.include pattern: %v", err)
+	}
+	excludePattern, err := compileServiceNamePattern(*serviceExclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collector.service.exclude pattern: %v", err)
+	}
+
+	var runAsPattern *regexp.Regexp
+	if *serviceRunAs != "" {
+		if runAsPattern, err = regexp.Compile(*serviceRunAs); err != nil {
+			return nil, fmt.Errorf("invalid collector.service.run-as pattern: %v", err)
+		}
+	}
+	if *serviceConfigRefreshInterval < 1 {
+		return nil, fmt.Errorf("collector.service.config-refresh-interval must be at least 1, got %d", *serviceConfigRefreshInterval)
+	}
+
+	// Local accounts may be qualified with the computer name, which is needed
+	// to tell them from domain accounts.
+	hostname, err := os.Hostname()
+	if err != nil {
+		serviceLog.Warnf("Couldn't get the computer name, accounts qualified with it will be classified as domain accounts: %v", err)
+	}
+	// The API is only queried on the local machine.
+	if remoteHost := RemoteHost(); remoteHost != "" {
+		if queryMode != serviceQueryModeWMI {
+			return nil, fmt.Errorf("collector.service.query-mode must be %q to query collector.wmi.remote-host", serviceQueryModeWMI)
+		}
+		hostname = remoteComputerName(remoteHost)
+	}
+
+	// The process ID changes whenever the service restarts, so it isn't part
+	// of the info labels unless explicitly requested, to avoid series churn.
+	infoLabels := []string{"name", "display_name", "run_as"}
+	if *serviceInfoProcessIDLabel {
+		serviceLog.Warn("Flag 'collector.service.info-process-id-label' is deprecated, use windows_service_process_id instead.")
+		infoLabels = []string{"name", "display_name", "process_id", "run_as"}
+	}
+	if *serviceSanitizeNames {
+		infoLabels = append(infoLabels, "sanitized_name")
+	}
+
+	switch queryMode {
+	case serviceQueryModeAPI:
+		serviceLog.Warn("API collection is enabled.")
+	case serviceQueryModeBoth:
+		serviceLog.Warn("Service query mode 'both' queries all services through both WMI and the API on every scrape, which is expensive. Only use it temporarily to validate a migration to 'api' mode!")
 	}
 
 	return &serviceCollector{
 		Information: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "info"),
 			"A metric with a constant '1' value labeled with service information",
-			[]string{"name", "display_name", "process_id", "run_as"},
-			nil,
+			infoLabels,
+			extraLabels,
+		),
+		ProcessID: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "process_id"),
+			"The process ID of the service, 0 if it is not running (ProcessId)",
+			[]string{"name"},
+			extraLabels,
+		),
+		Account: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "account"),
+			"A metric with a constant '1' value labeled with the account the service runs as, and its type",
+			[]string{"name", "account", "account_type"},
+			extraLabels,
 		),
 		State: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "state"),
 			"The state of the service (State)",
 			[]string{"name", "state"},
-			nil,
+			extraLabels,
 		),
 		StartMode: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "start_mode"),
 			"The start mode of the service (StartMode)",
 			[]string{"name", "start_mode"},
-			nil,
+			extraLabels,
 		),
 		Status: prometheus.NewDesc(
 			prometheus.BuildFQName(Namespace, subsystem, "status"),
 			"The status of the service (Status)",
 			[]string{"name", "status"},
+			extraLabels,
+		),
+		Up: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "up"),
+			"Whether the service is running",
+			[]string{"name"},
+			extraLabels,
+		),
+		DisplayInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "display_info"),
+			"A metric with a constant '1' value labeled with the name and display name of the service",
+			[]string{"name", "display_name"},
+			extraLabels,
+		),
+		StateTransitions: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "state_transitions_total"),
+			"Number of service state transitions observed between scrapes (API mode only)",
+			[]string{"name", "from", "to"},
+			extraLabels,
+		),
+		WaitHint: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "wait_hint_ms"),
+			"Estimated time required by the pending operation of the service, in milliseconds (API mode only)",
+			[]string{"name"},
+			extraLabels,
+		),
+		CheckPoint: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "checkpoint"),
+			"Progress of the pending operation of the service, incremented periodically by the service (API mode only)",
+			[]string{"name"},
+			extraLabels,
+		),
+		PendingScrapes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "pending_scrapes"),
+			"Number of consecutive scrapes which found the service in a pending state, 0 once it reaches a steady state (API mode only)",
+			[]string{"name"},
+			extraLabels,
+		),
+		FailureCommandConfigured: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "failure_command_configured"),
+			"Whether one of the recovery actions of the service runs a command (API mode only)",
+			[]string{"name"},
+			extraLabels,
+		),
+		FailureRebootConfigured: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "failure_reboot_configured"),
+			"Whether one of the recovery actions of the service reboots the computer (API mode only)",
+			[]string{"name"},
+			extraLabels,
+		),
+		Win32ExitCode: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "win32_exit_code"),
+			"Win32 error code reported by the service when it last started or stopped, for services not running",
+			[]string{"name"},
+			extraLabels,
+		),
+		ServiceSpecificExitCode: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "specific_exit_code"),
+			"Service-specific error code reported by the service when it last started or stopped, for services not running",
+			[]string{"name"},
+			extraLabels,
+		),
+		ModeMismatch: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "mode_mismatch"),
+			"Whether the WMI and API query modes disagree on a field of the service (both mode only)",
+			[]string{"name", "field"},
+			extraLabels,
+		),
+		AccessDenied: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "collector_access_denied"),
+			"Whether the service control manager denied access to the exporter (API mode only)",
 			nil,
+			extraLabels,
+		),
+		Count: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "count"),
+			"Number of services in each state and start mode",
+			[]string{"state", "start_mode"},
+			extraLabels,
+		),
+		CollectErrors: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "collect_errors_total"),
+			"Number of times a service was left out of a scrape because it couldn't be read, by stage (API mode only)",
+			[]string{"stage"},
+			extraLabels,
+		),
+		StateUnexpected: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "state_unexpected"),
+			"Whether the state of the service contradicts its start mode: automatic but not running, or disabled but running",
+			[]string{"name"},
+			extraLabels,
 		),
+		queryMode:        queryMode,
 		queryWhereClause: *serviceWhereClause,
+		includeList:      includeList,
+		includePattern:   includePattern,
+		excludePattern:   excludePattern,
+		runAsPattern:     runAsPattern,
+		upMetric:         *serviceRunningOnlyMetric,
+		displayMetric:    *serviceDisplayNameMetric,
+		sanitizeNames:    *serviceSanitizeNames,
+		processIDLabel:   *serviceInfoProcessIDLabel,
+		hostname:         hostname,
+		transitions:      newServiceTransitionTracker(),
+		pending:          newServicePendingTracker(),
+		configs:          newServiceConfigCache(*serviceConfigRefreshInterval),
+		collectErrors:    newServiceCollectErrors(),
 	}, nil
 }
 
 // Collect sends the metric values for each metric
 // to the provided prometheus Metric channel.
 func (c *serviceCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
-	if *useAPI {
-		if err := c.collectAPI(ch); err != nil {
-			log.Error("failed collecting API service metrics:", err)
+	switch c.queryMode {
+	case serviceQueryModeAPI:
+		if _, err := c.collectAPI(ctx, ch); err != nil {
+			serviceLog.Error("failed collecting API service metrics:", err)
+			return err
+		}
+	case serviceQueryModeBoth:
+		if err := c.collectBoth(ctx, ch); err != nil {
+			serviceLog.Error("failed collecting service metrics:", err)
 			return err
 		}
-	} else {
-		if err := c.collectWMI(ch); err != nil {
-			log.Error("failed collecting WMI service metrics:", err)
+	default:
+		if _, err := c.collectWMI(ctx, ch); err != nil {
+			serviceLog.Error("failed collecting WMI service metrics:", err)
 			return err
 		}
 	}
 	return nil
 }
 
+// collectBoth exposes the metrics of the WMI query mode, and the fields on
+// which the API query mode disagrees.
+func (c *serviceCollector) collectBoth(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	wmiFields, err := c.collectWMI(ctx, ch)
+	if err != nil {
+		return err
+	}
+
+	discard := make(chan prometheus.Metric)
+	done := make(chan struct{})
+	go func() {
+		for range discard {
+		}
+		close(done)
+	}()
+	apiFields, err := c.collectAPI(ctx, discard)
+	close(discard)
+	<-done
+	if err != nil {
+		return err
+	}
+
+	for _, mismatch := range serviceModeMismatches(wmiFields, apiFields) {
+		ch <- prometheus.MustNewConstMetric(
+			c.ModeMismatch,
+			prometheus.GaugeValue,
+			1.0,
+			mismatch.name,
+			mismatch.field,
+		)
+	}
+	return nil
+}
+
+// serviceFields holds the fields compared between the query modes, keyed by
+// lowercase service name.
+type serviceFields map[string]serviceModeFields
+
+type serviceModeFields struct {
+	state     string
+	startMode string
+}
+
+type serviceModeMismatch struct {
+	name  string
+	field string
+}
+
+// serviceModeMismatches returns the fields on which the query modes disagree,
+// for the services returned by both. Services missing from one of the modes,
+// e.g. because of the WMI where clause, are ignored.
+func serviceModeMismatches(wmiFields, apiFields serviceFields) []serviceModeMismatch {
+	var mismatches []serviceModeMismatch
+	for name, w := range wmiFields {
+		a, ok := apiFields[name]
+		if !ok {
+			continue
+		}
+		if w.state != a.state {
+			mismatches = append(mismatches, serviceModeMismatch{name: name, field: "state"})
+		}
+		if w.startMode != a.startMode {
+			mismatches = append(mismatches, serviceModeMismatch{name: name, field: "start_mode"})
+		}
+	}
+	return mismatches
+}
+
+// collectInfo exposes windows_service_info, with the sanitized name if enabled.
+func (c *serviceCollector) collectInfo(ch chan<- prometheus.Metric, name, displayName string, pid uint32, runAs string) {
+	labels := []string{name, displayName, runAs}
+	if c.processIDLabel {
+		labels = []string{name, displayName, strconv.FormatUint(uint64(pid), 10), runAs}
+	}
+	if c.sanitizeNames {
+		labels = append(labels, sanitizeServiceName(name))
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.Information,
+		prometheus.GaugeValue,
+		1.0,
+		labels...,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.ProcessID,
+		prometheus.GaugeValue,
+		float64(pid),
+		name,
+	)
+	if c.displayMetric {
+		ch <- prometheus.MustNewConstMetric(
+			c.DisplayInfo,
+			prometheus.GaugeValue,
+			1.0,
+			name,
+			displayName,
+		)
+	}
+
+	if runAs != "" {
+		ch <- prometheus.MustNewConstMetric(
+			c.Account,
+			prometheus.GaugeValue,
+			1.0,
+			name,
+			runAs,
+			serviceAccountType(runAs, c.hostname),
+		)
+	}
+}
+
+// serviceAccountType classifies the account a service runs as: one of the
+// built-in system, localservice and networkservice accounts, a virtual
+// account (NT Service\<name>), or a domain or local user account. hostname is
+// the name of the computer, which local accounts may be qualified with.
+func serviceAccountType(account, hostname string) string {
+	account = strings.ToLower(account)
+	switch account {
+	case "localsystem", ".\\localsystem", "nt authority\\system":
+		return "system"
+	case "nt authority\\localservice", "nt authority\\local service":
+		return "localservice"
+	case "nt authority\\networkservice", "nt authority\\network service":
+		return "networkservice"
+	}
+
+	if strings.Contains(account, "@") {
+		return "domain"
+	}
+	i := strings.Index(account, "\\")
+	if i < 0 {
+		return "local"
+	}
+	switch domain := account[:i]; {
+	case domain == "nt service":
+		return "virtual"
+	case domain == "." || domain == "" || domain == strings.ToLower(hostname):
+		return "local"
+	default:
+		return "domain"
+	}
+}
+
+// sanitizeServiceName replaces the characters of a service name other than
+// ASCII letters, digits and underscores by an underscore, so that it can be
+// used in joins and dashboard variables without escaping.
+func sanitizeServiceName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// collectExitCodes exposes the exit codes of a service. The service-specific
+// code is only meaningful when the Win32 code is ERROR_SERVICE_SPECIFIC_ERROR.
+func (c *serviceCollector) collectExitCodes(ch chan<- prometheus.Metric, name string, win32ExitCode, serviceSpecificExitCode uint32) {
+	ch <- prometheus.MustNewConstMetric(
+		c.Win32ExitCode,
+		prometheus.GaugeValue,
+		float64(win32ExitCode),
+		name,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.ServiceSpecificExitCode,
+		prometheus.GaugeValue,
+		float64(serviceSpecificExitCode),
+		name,
+	)
+}
+
+// queryServiceStatus returns the status of the service. Unlike
+// mgr.Service.Query, it keeps the checkpoint and wait hint of pending operations.
+func queryServiceStatus(handle windows.Handle) (*windows.SERVICE_STATUS_PROCESS, error) {
+	var status windows.SERVICE_STATUS_PROCESS
+	var needed uint32
+	err := windows.QueryServiceStatusEx(handle, windows.SC_STATUS_PROCESS_INFO, (*byte)(unsafe.Pointer(&status)), uint32(unsafe.Sizeof(status)), &needed)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// serviceStatusEntry is the name and status of a service, as listed by
+// EnumServicesStatusEx
+type serviceStatusEntry struct {
+	name   string
+	status windows.SERVICE_STATUS_PROCESS
+}
+
+// enumServices returns the name and status of the Win32 services in state
+// (SERVICE_ACTIVE, SERVICE_INACTIVE or SERVICE_STATE_ALL), in a single
+// EnumServicesStatusEx call instead of opening and querying each service.
+func enumServices(scm windows.Handle, state uint32) ([]serviceStatusEntry, error) {
+	var buf []byte
+	var needed, count, resume uint32
+	var err error
+	for {
+		var bufPtr *byte
+		if len(buf) > 0 {
+			bufPtr = &buf[0]
+		}
+		err = windows.EnumServicesStatusEx(scm, windows.SC_ENUM_PROCESS_INFO, windows.SERVICE_WIN32, state, bufPtr, uint32(len(buf)), &needed, &count, &resume, nil)
+		if err != windows.ERROR_MORE_DATA {
+			break
+		}
+		buf = make([]byte, needed)
+		resume = 0
+	}
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	entries := (*[1 << 20]windows.ENUM_SERVICE_STATUS_PROCESS)(unsafe.Pointer(&buf[0]))[:count:count]
+	services := make([]serviceStatusEntry, 0, count)
+	for _, entry := range entries {
+		services = append(services, serviceStatusEntry{
+			name:   windows.UTF16PtrToString(entry.ServiceName),
+			status: entry.ServiceStatusProcess,
+		})
+	}
+	return services, nil
+}
+
+// queryServiceConfig opens the service to read its configuration and
+// recovery actions (SERVICE_CONFIG_FAILURE_ACTIONS). On failure, it returns the
+// stage which failed.
+func queryServiceConfig(m *mgr.Mgr, name string) (serviceConfigEntry, string, error) {
+	s, err := m.OpenService(name)
+	if err != nil {
+		return serviceConfigEntry{}, serviceStageOpen, err
+	}
+	defer s.Close()
+
+	var entry serviceConfigEntry
+	if entry.config, err = s.Config(); err != nil {
+		return serviceConfigEntry{}, serviceStageConfig, err
+	}
+	entry.recoveryActions, entry.recoveryActionsErr = s.RecoveryActions()
+	return entry, "", nil
+}
+
+// isPendingServiceState reports whether the service is transitioning between
+// states, in which case it reports a checkpoint and wait hint.
+func isPendingServiceState(state uint32) bool {
+	switch state {
+	case windows.SERVICE_START_PENDING, windows.SERVICE_STOP_PENDING, windows.SERVICE_CONTINUE_PENDING, windows.SERVICE_PAUSE_PENDING:
+		return true
+	}
+	return false
+}
+
+// failureActionsConfigured reports whether the recovery actions of a service
+// run a command or reboot the computer.
+func failureActionsConfigured(actions []mgr.RecoveryAction) (command bool, reboot bool) {
+	for _, action := range actions {
+		switch action.Type {
+		case mgr.RunCommand:
+			command = true
+		case mgr.ComputerReboot:
+			reboot = true
+		}
+	}
+	return command, reboot
+}
+
+// serviceCounts counts the services in each combination of state and start
+// mode. All known combinations are included, so that their series don't
+// disappear when no service is in them.
+func serviceCounts(fields serviceFields) map[serviceModeFields]float64 {
+	counts := make(map[serviceModeFields]float64, len(allStates)*len(allStartModes))
+	for _, state := range allStates {
+		for _, startMode := range allStartModes {
+			counts[serviceModeFields{state, startMode}] = 0
+		}
+	}
+	for _, f := range fields {
+		counts[serviceModeFields{f.state, f.startMode}]++
+	}
+	return counts
+}
+
+// collectCounts exposes the number of services in each state and start mode,
+// for aggregate views without the per service series.
+func (c *serviceCollector) collectCounts(ch chan<- prometheus.Metric, fields serviceFields) {
+	for k, count := range serviceCounts(fields) {
+		ch <- prometheus.MustNewConstMetric(
+			c.Count,
+			prometheus.GaugeValue,
+			count,
+			k.state,
+			k.startMode,
+		)
+	}
+}
+
+// serviceStateUnexpected reports whether the state of the service contradicts
+// its start mode: an automatic service which isn't running, or a disabled one
+// which is. Services in a pending state are on their way, and not flagged.
+func serviceStateUnexpected(f serviceModeFields) bool {
+	if strings.HasSuffix(f.state, "pending") {
+		return false
+	}
+	switch f.startMode {
+	case "auto":
+		return f.state != "running"
+	case "disabled":
+		return f.state == "running"
+	}
+	return false
+}
+
+// collectStateUnexpected exposes, for each service, whether its state
+// contradicts its start mode.
+func (c *serviceCollector) collectStateUnexpected(ch chan<- prometheus.Metric, fields serviceFields) {
+	for name, f := range fields {
+		ch <- prometheus.MustNewConstMetric(
+			c.StateUnexpected,
+			prometheus.GaugeValue,
+			boolToFloat(serviceStateUnexpected(f)),
+			name,
+		)
+	}
+}
+
+// collectAccessDenied exposes whether connecting to the service control
+// manager failed because of missing permissions, as opposed to any other
+// failure, so that alerts can target misconfigured deployments.
+func (c *serviceCollector) collectAccessDenied(ch chan<- prometheus.Metric, err error) {
+	denied := errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	if denied {
+		c.accessDeniedOnce.Do(func() {
+			serviceLog.Error("Access to the service control manager was denied. The exporter likely needs to run as a service, or as an administrator.")
+		})
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.AccessDenied,
+		prometheus.GaugeValue,
+		boolToFloat(denied),
+	)
+}
+
+// remoteComputerName returns the computer name of the remote host, which
+// qualifies its local accounts. It is read from Win32_ComputerSystem, and
+// derived from the host name when that fails.
+func remoteComputerName(host string) string {
+	var systems []Win32_ComputerSystem
+	err := wmiQuery(queryAll(&systems), &systems)
+	if err == nil && len(systems) > 0 && systems[0].Name != "" {
+		return systems[0].Name
+	}
+	name := computerNameFromHost(host)
+	serviceLog.Warnf("Couldn't get the computer name of %s, using %q: %v", host, name, err)
+	return name
+}
+
+// computerNameFromHost returns the first label of a host name, which is
+// usually the computer name. An IP address gives none, in which case accounts
+// qualified with the computer name are classified as domain accounts.
+func computerNameFromHost(host string) string {
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	return strings.SplitN(host, ".", 2)[0]
+}
+
+// compileServiceNamePattern compiles a pattern matching whole service names,
+// case-insensitively as Windows compares them.
+func compileServiceNamePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(fmt.Sprintf("^(?i:%s)$", pattern))
+}
+
+// includeName reports whether the service is included, according to the
+// include and exclude patterns.
+func (c *serviceCollector) includeName(name string) bool {
+	return c.includePattern.MatchString(name) && !c.excludePattern.MatchString(name)
+}
+
+// includeRunAs reports whether services running as the given account are
+// included, according to the run-as pattern.
+func (c *serviceCollector) includeRunAs(runAs string) bool {
+	return c.runAsPattern == nil || c.runAsPattern.MatchString(runAs)
+}
+
+// A serviceIncludeList holds the names of the services to include, read from
+// collector.service.include-file. When watched, the file is re-read on the
+// first scrape after it was modified.
+type serviceIncludeList struct {
+	path  string
+	watch bool
+
+	mu      sync.Mutex
+	modTime time.Time
+	list    []string
+}
+
+func newServiceIncludeList(path string, watch bool) (*serviceIncludeList, error) {
+	l := &serviceIncludeList{path: path, watch: watch}
+	if path == "" {
+		return l, nil
+	}
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		serviceLog.Warnf("Service include file %s doesn't exist, including all services", path)
+		return l, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid collector.service.include-file: %v", err)
+	}
+	if err := l.load(fi.ModTime()); err != nil {
+		return nil, fmt.Errorf("invalid collector.service.include-file: %v", err)
+	}
+	return l, nil
+}
+
+func (l *serviceIncludeList) load(modTime time.Time) error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	list, err := parseServiceIncludeList(f)
+	if err != nil {
+		return err
+	}
+	l.list = list
+	l.modTime = modTime
+	serviceLog.Debugf("Read %d services from %s", len(list), l.path)
+	return nil
+}
+
+// names returns the names of the services to include, or nil to include all
+// services.
+func (l *serviceIncludeList) names() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.watch && l.path != "" {
+		fi, err := os.Stat(l.path)
+		switch {
+		case os.IsNotExist(err):
+			l.list = nil
+			l.modTime = time.Time{}
+		case err != nil:
+			serviceLog.Warnf("Couldn't stat service include file %s, keeping the previous list: %v", l.path, err)
+		case !fi.ModTime().Equal(l.modTime):
+			if err := l.load(fi.ModTime()); err != nil {
+				serviceLog.Warnf("Couldn't read service include file %s, keeping the previous list: %v", l.path, err)
+			}
+		}
+	}
+	return l.list
+}
+
+// parseServiceIncludeList reads service names, one per line. Blank lines and
+// lines starting with # are ignored.
+func parseServiceIncludeList(r io.Reader) ([]string, error) {
+	var names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names, scanner.Err()
+}
+
+// serviceWhereClauseWithNames restricts a WQL where-clause, passed through as
+// is, to the given service names, which are escaped.
+func serviceWhereClauseWithNames(where string, names []string) string {
+	if len(names) == 0 {
+		return where
+	}
+	clause := wqlEqualsAny("Name", names)
+	if where == "" {
+		return clause
+	}
+	return "(" + where + ") AND " + clause
+}
+
+// filterServiceNames returns the services whose name is in names, compared
+// case-insensitively, or all services if names is empty.
+func filterServiceNames(services []string, names []string) []string {
+	if len(names) == 0 {
+		return services
+	}
+	include := make(map[string]bool, len(names))
+	for _, name := range names {
+		include[strings.ToLower(name)] = true
+	}
+	filtered := make([]string, 0, len(names))
+	for _, service := range services {
+		if include[strings.ToLower(service)] {
+			filtered = append(filtered, service)
+		}
+	}
+	return filtered
+}
+
+// serviceLabelNames are the label names used by the service collector, which
+// extra labels may not override.
+var serviceLabelNames = []string{"name", "display_name", "process_id", "run_as", "sanitized_name", "account", "account_type", "state", "start_mode", "status", "from", "to", "field"}
+
+// parseServiceExtraLabels parses key=value pairs into labels, validating the
+// label names.
+func parseServiceExtraLabels(pairs []string) (prometheus.Labels, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	labels := make(prometheus.Labels, len(pairs))
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid collector.service.extra-labels value %q, expected key=value", pair)
+		}
+		name := parts[0]
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			return nil, fmt.Errorf("invalid collector.service.extra-labels label name %q", name)
+		}
+		for _, reserved := range serviceLabelNames {
+			if name == reserved {
+				return nil, fmt.Errorf("collector.service.extra-labels label %q is already used by the service collector", name)
+			}
+		}
+		if _, ok := labels[name]; ok {
+			return nil, fmt.Errorf("duplicate collector.service.extra-labels label %q", name)
+		}
+		labels[name] = parts[1]
+	}
+	return labels, nil
+}
+
+// serviceQueryMode reconciles the query-mode flag with the deprecated use-api
+// flag, query-mode winning when both are set.
+func serviceQueryMode(queryMode string, useAPI bool) string {
+	if queryMode != "" {
+		return queryMode
+	}
+	if useAPI {
+		return serviceQueryModeAPI
+	}
+	return serviceQueryModeWMI
+}
+
 // Win32_Service docs:
 // - https://msdn.microsoft.com/en-us/library/aa394418(v=vs.85).aspx
 type Win32_Service struct {
-	DisplayName string
-	Name        string
-	ProcessId   uint32
-	State       string
-	Status      string
-	StartMode   string
-	StartName   *string
+	DisplayName             string
+	Name                    string
+	ProcessId               uint32
+	State                   string
+	Status                  string
+	StartMode               string
+	StartName               *string
+	ExitCode                uint32
+	ServiceSpecificExitCode uint32
 }
 
 var (
@@ -158,28 +1002,45 @@ var (
 	}
 )
 
-func (c *serviceCollector) collectWMI(ch chan<- prometheus.Metric) error {
+func (c *serviceCollector) collectWMI(ctx *ScrapeContext, ch chan<- prometheus.Metric) (serviceFields, error) {
 	var dst []Win32_Service
-	q := queryAllWhere(&dst, c.queryWhereClause)
-	if err := wmi.Query(q, &dst); err != nil {
-		return err
+	q := queryAllWhere(&dst, serviceWhereClauseWithNames(c.queryWhereClause, c.includeList.names()))
+	if err := wmiQuery(q, &dst); err != nil {
+		return nil, err
 	}
+	fields := make(serviceFields, len(dst))
 	for _, service := range dst {
-		pid := fmt.Sprintf("%d", uint64(service.ProcessId))
+		if !c.includeName(service.Name) {
+			continue
+		}
 
 		runAs := ""
 		if service.StartName != nil {
 			runAs = *service.StartName
 		}
-		ch <- prometheus.MustNewConstMetric(
-			c.Information,
-			prometheus.GaugeValue,
-			1.0,
-			strings.ToLower(service.Name),
-			service.DisplayName,
-			pid,
-			runAs,
-		)
+		if !c.includeRunAs(runAs) {
+			continue
+		}
+
+		fields[strings.ToLower(service.Name)] = serviceModeFields{
+			state:     strings.ToLower(service.State),
+			startMode: strings.ToLower(service.StartMode),
+		}
+
+		c.collectInfo(ch, strings.ToLower(service.Name), service.DisplayName, service.ProcessId, runAs)
+
+		if c.upMetric {
+			ch <- prometheus.MustNewConstMetric(
+				c.Up,
+				prometheus.GaugeValue,
+				boolToFloat(strings.ToLower(service.State) == "running"),
+				strings.ToLower(service.Name),
+			)
+		}
+
+		if strings.ToLower(service.State) != "running" {
+			c.collectExitCodes(ch, strings.ToLower(service.Name), service.ExitCode, service.ServiceSpecificExitCode)
+		}
 
 		for _, state := range allStates {
 			isCurrentState := 0.0
@@ -223,59 +1084,93 @@ func (c *serviceCollector) collectWMI(ch chan<- prometheus.Metric) error {
 			)
 		}
 	}
-	return nil
+	c.collectCounts(ch, fields)
+	c.collectStateUnexpected(ch, fields)
+	return fields, nil
 }
 
-func (c *serviceCollector) collectAPI(ch chan<- prometheus.Metric) error {
+func (c *serviceCollector) collectAPI(ctx *ScrapeContext, ch chan<- prometheus.Metric) (serviceFields, error) {
 	svcmgrConnection, err := mgr.Connect()
 	if err != nil {
-		return err
+		c.collectAccessDenied(ch, err)
+		return nil, err
 	}
 	defer svcmgrConnection.Disconnect()
 
-	// List All Services from the Services Manager
-	serviceList, err := svcmgrConnection.ListServices()
+	// List all services with their status from the Services Manager, in a
+	// single call rather than one query per service
+	services, err := enumServices(svcmgrConnection.Handle, windows.SERVICE_STATE_ALL)
+	c.collectAccessDenied(ch, err)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	statuses := make(map[string]windows.SERVICE_STATUS_PROCESS, len(services))
+	serviceList := make([]string, 0, len(services))
+	for _, s := range services {
+		// Filtered before querying the configuration of the services, which
+		// is the bulk of the cost of a scrape.
+		if !c.includeName(s.name) {
+			continue
+		}
+		statuses[s.name] = s.status
+		serviceList = append(serviceList, s.name)
 	}
 
+	states := make(map[string]string, len(serviceList))
+	fields := make(serviceFields, len(serviceList))
+	serviceList = filterServiceNames(serviceList, c.includeList.names())
+	scrape := c.configs.startScrape(serviceList)
+
 	// Iterate through the Services List
 	for _, service := range serviceList {
-		// Retrieve handle for each service
-		serviceHandle, err := svcmgrConnection.OpenService(service)
-		if err != nil {
-			continue
+		serviceStatus := statuses[service]
+
+		// Get Service Configuration, unless cached. Only then is a handle to
+		// the service needed.
+		cached, ok := c.configs.get(service, scrape)
+		if !ok {
+			var stage string
+			if cached, stage, err = queryServiceConfig(svcmgrConnection, service); err != nil {
+				// The service was likely removed, or its security descriptor
+				// denies access to the exporter
+				serviceLog.Debugf("Could not %s service %s: %v. Skipping", stage, service, err)
+				c.collectErrors.inc(stage)
+				c.configs.invalidate(service)
+				continue
+			}
+			c.configs.put(service, cached, scrape)
 		}
+		serviceConfig := cached.config
 
-		// Get Service Configuration
-		serviceConfig, err := serviceHandle.Config()
-		if err != nil {
-			_ = serviceHandle.Close()
+		if !c.includeRunAs(serviceConfig.ServiceStartName) {
 			continue
 		}
 
-		// Get Service Current Status
-		serviceStatus, err := serviceHandle.Query()
-		if err != nil {
-			_ = serviceHandle.Close()
-			continue
+		states[strings.ToLower(service)] = apiStateValues[uint(serviceStatus.CurrentState)]
+		fields[strings.ToLower(service)] = serviceModeFields{
+			state:     apiStateValues[uint(serviceStatus.CurrentState)],
+			startMode: apiStartModeValues[serviceConfig.StartType],
 		}
 
-		pid := fmt.Sprintf("%d", uint64(serviceStatus.ProcessId))
+		c.collectInfo(ch, strings.ToLower(service), serviceConfig.DisplayName, serviceStatus.ProcessId, serviceConfig.ServiceStartName)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.Information,
-			prometheus.GaugeValue,
-			1.0,
-			strings.ToLower(service),
-			serviceConfig.DisplayName,
-			pid,
-			serviceConfig.ServiceStartName,
-		)
+		if c.upMetric {
+			ch <- prometheus.MustNewConstMetric(
+				c.Up,
+				prometheus.GaugeValue,
+				boolToFloat(serviceStatus.CurrentState == windows.SERVICE_RUNNING),
+				strings.ToLower(service),
+			)
+		}
+
+		if serviceStatus.CurrentState != windows.SERVICE_RUNNING {
+			c.collectExitCodes(ch, strings.ToLower(service), serviceStatus.Win32ExitCode, serviceStatus.ServiceSpecificExitCode)
+		}
 
 		for _, state := range apiStateValues {
 			isCurrentState := 0.0
-			if state == apiStateValues[uint(serviceStatus.State)] {
+			if state == apiStateValues[uint(serviceStatus.CurrentState)] {
 				isCurrentState = 1.0
 			}
 			ch <- prometheus.MustNewConstMetric(
@@ -300,6 +1195,269 @@ func (c *serviceCollector) collectAPI(ch chan<- prometheus.Metric) error {
 				startMode,
 			)
 		}
+
+		if cached.recoveryActionsErr != nil {
+			serviceLog.Debugf("Could not query recovery actions of service %s: %v", service, cached.recoveryActionsErr)
+		} else {
+			command, reboot := failureActionsConfigured(cached.recoveryActions)
+			ch <- prometheus.MustNewConstMetric(
+				c.FailureCommandConfigured,
+				prometheus.GaugeValue,
+				boolToFloat(command),
+				strings.ToLower(service),
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.FailureRebootConfigured,
+				prometheus.GaugeValue,
+				boolToFloat(reboot),
+				strings.ToLower(service),
+			)
+		}
+
+		if isPendingServiceState(serviceStatus.CurrentState) {
+			ch <- prometheus.MustNewConstMetric(
+				c.WaitHint,
+				prometheus.GaugeValue,
+				float64(serviceStatus.WaitHint),
+				strings.ToLower(service),
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.CheckPoint,
+				prometheus.GaugeValue,
+				float64(serviceStatus.CheckPoint),
+				strings.ToLower(service),
+			)
+		}
 	}
-	return nil
+
+	for transition, count := range c.transitions.update(states) {
+		ch <- prometheus.MustNewConstMetric(
+			c.StateTransitions,
+			prometheus.CounterValue,
+			count,
+			transition.name,
+			transition.from,
+			transition.to,
+		)
+	}
+	for name, count := range c.pending.update(states) {
+		ch <- prometheus.MustNewConstMetric(
+			c.PendingScrapes,
+			prometheus.GaugeValue,
+			count,
+			name,
+		)
+	}
+	for stage, count := range c.collectErrors.counts() {
+		ch <- prometheus.MustNewConstMetric(
+			c.CollectErrors,
+			prometheus.CounterValue,
+			count,
+			stage,
+		)
+	}
+	c.collectCounts(ch, fields)
+	c.collectStateUnexpected(ch, fields)
+	return fields, nil
+}
+
+// Stages at which reading a service can fail, in the api query mode. The state
+// and process ID are listed for all services at once, and can't fail alone.
+const (
+	serviceStageOpen   = "open"
+	serviceStageConfig = "config"
+)
+
+// serviceCollectErrors counts the services left out of scrapes, by stage. It
+// is safe for concurrent use.
+type serviceCollectErrors struct {
+	mu     sync.Mutex
+	errors map[string]float64
+}
+
+func newServiceCollectErrors() *serviceCollectErrors {
+	return &serviceCollectErrors{errors: map[string]float64{
+		serviceStageOpen:   0,
+		serviceStageConfig: 0,
+	}}
+}
+
+func (e *serviceCollectErrors) inc(stage string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errors[stage]++
+}
+
+// counts returns a copy of the counters
+func (e *serviceCollectErrors) counts() map[string]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	counts := make(map[string]float64, len(e.errors))
+	for stage, count := range e.errors {
+		counts[stage] = count
+	}
+	return counts
+}
+
+type serviceTransition struct {
+	name string
+	from string
+	to   string
+}
+
+// serviceTransitionTracker remembers the state of each service across scrapes,
+// and counts the state transitions observed. It is safe for concurrent use.
+type serviceTransitionTracker struct {
+	mu          sync.Mutex
+	states      map[string]string
+	transitions map[serviceTransition]float64
+}
+
+func newServiceTransitionTracker() *serviceTransitionTracker {
+	return &serviceTransitionTracker{
+		states:      make(map[string]string),
+		transitions: make(map[serviceTransition]float64),
+	}
+}
+
+// update records the current state of all services, and returns a copy of
+// the transition counters. Services which are no longer present are
+// forgotten, so their counters start over if they reappear.
+func (t *serviceTransitionTracker) update(states map[string]string) map[serviceTransition]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, state := range states {
+		if previous, ok := t.states[name]; ok && previous != state {
+			t.transitions[serviceTransition{name: name, from: previous, to: state}]++
+		}
+		t.states[name] = state
+	}
+	for name := range t.states {
+		if _, ok := states[name]; !ok {
+			delete(t.states, name)
+		}
+	}
+
+	result := make(map[serviceTransition]float64, len(t.transitions))
+	for transition, count := range t.transitions {
+		if _, ok := states[transition.name]; !ok {
+			delete(t.transitions, transition)
+			continue
+		}
+		result[transition] = count
+	}
+	return result
+}
+
+// servicePendingTracker counts, for each service, the consecutive scrapes
+// which found it in a pending state, to detect services stuck starting or
+// stopping.
+type servicePendingTracker struct {
+	mu     sync.Mutex
+	counts map[string]float64
+}
+
+func newServicePendingTracker() *servicePendingTracker {
+	return &servicePendingTracker{counts: make(map[string]float64)}
+}
+
+// update records the current state of all services, and returns a copy of
+// the counters. Services which are no longer present are forgotten.
+func (t *servicePendingTracker) update(states map[string]string) map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, state := range states {
+		if strings.HasSuffix(state, "pending") {
+			t.counts[name]++
+		} else {
+			t.counts[name] = 0
+		}
+	}
+
+	result := make(map[string]float64, len(states))
+	for name, count := range t.counts {
+		if _, ok := states[name]; !ok {
+			delete(t.counts, name)
+			continue
+		}
+		result[name] = count
+	}
+	return result
+}
+
+// serviceConfigEntry holds the configuration of a service, which rarely changes.
+type serviceConfigEntry struct {
+	config             mgr.Config
+	recoveryActions    []mgr.RecoveryAction
+	recoveryActionsErr error
+}
+
+type cachedServiceConfigEntry struct {
+	serviceConfigEntry
+	scrape int
+}
+
+// serviceConfigCache caches the configuration of services for a number of
+// scrapes, so that only their status is queried on every scrape. It is safe
+// for concurrent use.
+type serviceConfigCache struct {
+	mu       sync.Mutex
+	interval int
+	scrape   int
+	configs  map[string]cachedServiceConfigEntry
+}
+
+func newServiceConfigCache(interval int) *serviceConfigCache {
+	return &serviceConfigCache{
+		interval: interval,
+		configs:  make(map[string]cachedServiceConfigEntry),
+	}
+}
+
+// startScrape returns the number of the new scrape, and forgets the services
+// which are no longer listed.
+func (c *serviceConfigCache) startScrape(services []string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	listed := make(map[string]bool, len(services))
+	for _, name := range services {
+		listed[name] = true
+	}
+	for name := range c.configs {
+		if !listed[name] {
+			delete(c.configs, name)
+		}
+	}
+	c.scrape++
+	return c.scrape
+}
+
+// get returns the cached configuration of the service, and whether it is
+// still fresh at the given scrape.
+func (c *serviceConfigCache) get(name string, scrape int) (serviceConfigEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.configs[name]
+	if !ok || scrape-cached.scrape >= c.interval {
+		return serviceConfigEntry{}, false
+	}
+	return cached.serviceConfigEntry, true
+}
+
+func (c *serviceConfigCache) put(name string, config serviceConfigEntry, scrape int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.configs[name] = cachedServiceConfigEntry{serviceConfigEntry: config, scrape: scrape}
+}
+
+func (c *serviceConfigCache) invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.configs, name)
 }
@@ -1,9 +1,553 @@
 package collector
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
 )
 
+func TestServiceQueryDefaultNamespace(t *testing.T) {
+	var dst []Win32_Service
+	expected := "SELECT * FROM Win32_Service WHERE Name = 'wuauserv'"
+	if q := queryAllWhere(&dst, "Name = 'wuauserv'"); q != expected {
+		t.Errorf("expected query %q, got %q", expected, q)
+	}
+	// The service collector queries through wmiQuery, which must keep
+	// targeting the namespace Win32_Service lives in.
+	if wmiDefaultNamespace != `root\cimv2` {
+		t.Errorf("expected default namespace root\\cimv2, got %q", wmiDefaultNamespace)
+	}
+}
+
+func TestServiceQueryMode(t *testing.T) {
+	cases := []struct {
+		queryMode string
+		useAPI    bool
+		expected  string
+	}{
+		{"", false, serviceQueryModeWMI},
+		{"", true, serviceQueryModeAPI},
+		{serviceQueryModeAPI, false, serviceQueryModeAPI},
+		{serviceQueryModeWMI, true, serviceQueryModeWMI},
+	}
+	for _, c := range cases {
+		if got := serviceQueryMode(c.queryMode, c.useAPI); got != c.expected {
+			t.Errorf("serviceQueryMode(%q, %v): expected %q, got %q", c.queryMode, c.useAPI, c.expected, got)
+		}
+	}
+}
+
+func TestServiceIncludeRunAs(t *testing.T) {
+	c := &serviceCollector{}
+	if !c.includeRunAs("NT AUTHORITY\\LocalService") {
+		t.Error("expected all services to be included without a run-as pattern")
+	}
+
+	c.runAsPattern = regexp.MustCompile("^LocalSystem$")
+	cases := map[string]bool{
+		"LocalSystem":                true,
+		"localsystem":                false,
+		"NT AUTHORITY\\LocalService": false,
+		"":                           false,
+	}
+	for runAs, expected := range cases {
+		if got := c.includeRunAs(runAs); got != expected {
+			t.Errorf("includeRunAs(%q): expected %v, got %v", runAs, expected, got)
+		}
+	}
+}
+
+func TestComputerNameFromHost(t *testing.T) {
+	cases := map[string]string{
+		"sql01.corp.example.com": "sql01",
+		"SQL01":                  "SQL01",
+		"10.1.2.3":               "",
+		"fe80::1":                "",
+	}
+	for host, expected := range cases {
+		if got := computerNameFromHost(host); got != expected {
+			t.Errorf("computerNameFromHost(%q): expected %q, got %q", host, expected, got)
+		}
+	}
+}
+
+func TestServiceIncludeName(t *testing.T) {
+	cases := []struct {
+		include, exclude string
+		name             string
+		expected         bool
+	}{
+		{".+", "", "Spooler", true},
+		{"mssql.*", "", "MSSQLSERVER", true},
+		{"mssql.*", "", "SQLWriter", false},
+		{"sql", "", "MSSQLSERVER", false},
+		{".+", "wuauserv|bits", "BITS", false},
+		{".+", "wuauserv|bits", "BITSAdmin", true},
+		{"MSSQL.*", "MSSQL\\$.*", "MSSQL$EXPRESS", false},
+	}
+	for _, c := range cases {
+		include, err := compileServiceNamePattern(c.include)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exclude, err := compileServiceNamePattern(c.exclude)
+		if err != nil {
+			t.Fatal(err)
+		}
+		coll := &serviceCollector{includePattern: include, excludePattern: exclude}
+		if got := coll.includeName(c.name); got != c.expected {
+			t.Errorf("include %q, exclude %q: includeName(%q): expected %v, got %v", c.include, c.exclude, c.name, c.expected, got)
+		}
+	}
+}
+
+func TestFailureActionsConfigured(t *testing.T) {
+	cases := []struct {
+		actions []mgr.RecoveryAction
+		command bool
+		reboot  bool
+	}{
+		{nil, false, false},
+		{[]mgr.RecoveryAction{{Type: mgr.ServiceRestart}, {Type: mgr.NoAction}}, false, false},
+		{[]mgr.RecoveryAction{{Type: mgr.ServiceRestart}, {Type: mgr.RunCommand}}, true, false},
+		{[]mgr.RecoveryAction{{Type: mgr.ServiceRestart}, {Type: mgr.ServiceRestart}, {Type: mgr.ComputerReboot}}, false, true},
+	}
+	for i, c := range cases {
+		command, reboot := failureActionsConfigured(c.actions)
+		if command != c.command || reboot != c.reboot {
+			t.Errorf("case %d: expected (%v, %v), got (%v, %v)", i, c.command, c.reboot, command, reboot)
+		}
+	}
+}
+
+func TestParseServiceExtraLabels(t *testing.T) {
+	labels, err := parseServiceExtraLabels([]string{"environment=production", "role=dc=east", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"environment": "production", "role": "dc=east", "empty": ""}
+	if !reflect.DeepEqual(map[string]string(labels), expected) {
+		t.Errorf("expected %v, got %v", expected, labels)
+	}
+
+	if labels, err := parseServiceExtraLabels(nil); err != nil || labels != nil {
+		t.Errorf("expected no labels, got %v, %v", labels, err)
+	}
+
+	for _, invalid := range [][]string{
+		{"environment"},
+		{"1st=production"},
+		{"__name__=production"},
+		{"state=production"},
+		{"role=a", "role=b"},
+	} {
+		if _, err := parseServiceExtraLabels(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestSanitizeServiceName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"wuauserv":         "wuauserv",
+		"mssql$sqlexpress": "mssql_sqlexpress",
+		"cdpusersvc_4a1f2": "cdpusersvc_4a1f2",
+		"my service (x86)": "my_service__x86_",
+		"dienst-ü":         "dienst__",
+	} {
+		if sanitized := sanitizeServiceName(name); sanitized != expected {
+			t.Errorf("sanitizeServiceName(%q) = %q, want %q", name, sanitized, expected)
+		}
+	}
+}
+
+func TestServiceModeMismatches(t *testing.T) {
+	wmiFields := serviceFields{
+		"agree":    {state: "running", startMode: "auto"},
+		"state":    {state: "running", startMode: "auto"},
+		"both":     {state: "stopped", startMode: "manual"},
+		"wmi only": {state: "running", startMode: "auto"},
+	}
+	apiFields := serviceFields{
+		"agree":    {state: "running", startMode: "auto"},
+		"state":    {state: "stop pending", startMode: "auto"},
+		"both":     {state: "running", startMode: "auto"},
+		"api only": {state: "running", startMode: "auto"},
+	}
+	expected := map[serviceModeMismatch]bool{
+		{name: "state", field: "state"}:     true,
+		{name: "both", field: "state"}:      true,
+		{name: "both", field: "start_mode"}: true,
+	}
+
+	mismatches := serviceModeMismatches(wmiFields, apiFields)
+	if len(mismatches) != len(expected) {
+		t.Fatalf("expected %d mismatches, got %v", len(expected), mismatches)
+	}
+	for _, mismatch := range mismatches {
+		if !expected[mismatch] {
+			t.Errorf("unexpected mismatch %v", mismatch)
+		}
+	}
+}
+
+func TestServiceTransitionTracker(t *testing.T) {
+	tracker := newServiceTransitionTracker()
+
+	scrapes := []struct {
+		states   map[string]string
+		expected map[serviceTransition]float64
+	}{
+		{
+			states:   map[string]string{"foo": "running", "bar": "stopped"},
+			expected: map[serviceTransition]float64{},
+		},
+		{
+			states: map[string]string{"foo": "stopped", "bar": "stopped"},
+			expected: map[serviceTransition]float64{
+				{name: "foo", from: "running", to: "stopped"}: 1,
+			},
+		},
+		{
+			states: map[string]string{"foo": "running", "bar": "stopped"},
+			expected: map[serviceTransition]float64{
+				{name: "foo", from: "running", to: "stopped"}: 1,
+				{name: "foo", from: "stopped", to: "running"}: 1,
+			},
+		},
+		// Disappearing service is forgotten
+		{
+			states:   map[string]string{"bar": "stopped"},
+			expected: map[serviceTransition]float64{},
+		},
+		// Reappearing service starts over, without a transition
+		{
+			states:   map[string]string{"foo": "stopped", "bar": "running"},
+			expected: map[serviceTransition]float64{{name: "bar", from: "stopped", to: "running"}: 1},
+		},
+	}
+
+	for i, scrape := range scrapes {
+		got := tracker.update(scrape.states)
+		if !reflect.DeepEqual(got, scrape.expected) {
+			t.Errorf("scrape %d: expected %v, got %v", i, scrape.expected, got)
+		}
+	}
+}
+
 func BenchmarkServiceCollector(b *testing.B) {
 	benchmarkCollector(b, "service", NewserviceCollector)
 }
+
+func TestServiceConfigCache(t *testing.T) {
+	cache := newServiceConfigCache(3)
+	entry := serviceConfigEntry{config: mgr.Config{DisplayName: "Windows Update"}}
+
+	scrape := cache.startScrape([]string{"wuauserv"})
+	if _, ok := cache.get("wuauserv", scrape); ok {
+		t.Fatal("expected an empty cache")
+	}
+	cache.put("wuauserv", entry, scrape)
+
+	for i := 0; i < 2; i++ {
+		scrape = cache.startScrape([]string{"wuauserv"})
+		if cached, ok := cache.get("wuauserv", scrape); !ok || cached.config.DisplayName != "Windows Update" {
+			t.Errorf("scrape %d: expected a cache hit, got %v, %v", scrape, cached, ok)
+		}
+	}
+	scrape = cache.startScrape([]string{"wuauserv"})
+	if _, ok := cache.get("wuauserv", scrape); ok {
+		t.Errorf("scrape %d: expected the entry to be refreshed", scrape)
+	}
+
+	cache.put("wuauserv", entry, scrape)
+	cache.invalidate("wuauserv")
+	if _, ok := cache.get("wuauserv", scrape); ok {
+		t.Error("expected an invalidated entry to be refreshed")
+	}
+
+	cache.put("wuauserv", entry, scrape)
+	scrape = cache.startScrape(nil)
+	if _, ok := cache.get("wuauserv", scrape); ok {
+		t.Error("expected a service which is no longer listed to be forgotten")
+	}
+}
+
+func TestServiceAccountType(t *testing.T) {
+	cases := map[string]string{
+		"LocalSystem":                 "system",
+		`NT AUTHORITY\SYSTEM`:         "system",
+		`NT AUTHORITY\LocalService`:   "localservice",
+		`NT AUTHORITY\Local Service`:  "localservice",
+		`NT AUTHORITY\NetworkService`: "networkservice",
+		`NT Service\MSSQLSERVER`:      "virtual",
+		`.\svc_backup`:                "local",
+		`HOST01\svc_backup`:           "local",
+		"svc_backup":                  "local",
+		`CORP\svc_backup`:             "domain",
+		`CORP\gmsa_sql$`:              "domain",
+		"svc_backup@corp.example.com": "domain",
+	}
+	for account, want := range cases {
+		if got := serviceAccountType(account, "host01"); got != want {
+			t.Errorf("serviceAccountType(%q) = %q, want %q", account, got, want)
+		}
+	}
+}
+
+func TestParseServiceIncludeList(t *testing.T) {
+	list, err := parseServiceIncludeList(strings.NewReader("# Web servers\nW3SVC\n\n  WAS  \nmssql$sqlexpress\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"W3SVC", "WAS", "mssql$sqlexpress"}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("got %v, want %v", list, want)
+	}
+}
+
+func TestServiceWhereClauseWithNames(t *testing.T) {
+	cases := []struct {
+		where string
+		names []string
+		want  string
+	}{
+		{"StartMode = 'Auto'", nil, "StartMode = 'Auto'"},
+		{"", []string{"W3SVC"}, "(Name = 'W3SVC')"},
+		{"StartMode = 'Auto'", []string{"W3SVC", "WAS"}, "(StartMode = 'Auto') AND (Name = 'W3SVC' OR Name = 'WAS')"},
+		{"", []string{`it's`}, `(Name = 'it\'s')`},
+		{"", []string{`a\b`, `c'd`}, `(Name = 'a\\b' OR Name = 'c\'d')`},
+	}
+	for _, tc := range cases {
+		if got := serviceWhereClauseWithNames(tc.where, tc.names); got != tc.want {
+			t.Errorf("serviceWhereClauseWithNames(%q, %v) = %q, want %q", tc.where, tc.names, got, tc.want)
+		}
+	}
+}
+
+func TestFilterServiceNames(t *testing.T) {
+	services := []string{"W3SVC", "WAS", "wuauserv"}
+	if got := filterServiceNames(services, nil); !reflect.DeepEqual(got, services) {
+		t.Errorf("expected all services without names, got %v", got)
+	}
+	if got := filterServiceNames(services, []string{"w3svc", "Spooler"}); !reflect.DeepEqual(got, []string{"W3SVC"}) {
+		t.Errorf("expected W3SVC, got %v", got)
+	}
+}
+
+func TestServiceIncludeListWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "windows_exporter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "services.txt")
+	if err := ioutil.WriteFile(path, []byte("W3SVC\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := newServiceIncludeList(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.names(); !reflect.DeepEqual(got, []string{"W3SVC"}) {
+		t.Errorf("expected W3SVC, got %v", got)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("W3SVC\nWAS\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.names(); !reflect.DeepEqual(got, []string{"W3SVC", "WAS"}) {
+		t.Errorf("expected the modified list, got %v", got)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.names(); got != nil {
+		t.Errorf("expected no filtering once the file is removed, got %v", got)
+	}
+}
+
+func TestServicePendingTracker(t *testing.T) {
+	tracker := newServicePendingTracker()
+
+	scrapes := []struct {
+		states   map[string]string
+		expected map[string]float64
+	}{
+		{
+			states:   map[string]string{"foo": "start pending", "bar": "running"},
+			expected: map[string]float64{"foo": 1, "bar": 0},
+		},
+		{
+			states:   map[string]string{"foo": "start pending", "bar": "stop pending"},
+			expected: map[string]float64{"foo": 2, "bar": 1},
+		},
+		// Reaching a steady state resets the counter
+		{
+			states:   map[string]string{"foo": "running", "bar": "stop pending"},
+			expected: map[string]float64{"foo": 0, "bar": 2},
+		},
+		// Disappearing service is forgotten
+		{
+			states:   map[string]string{"bar": "stopped"},
+			expected: map[string]float64{"bar": 0},
+		},
+		{
+			states:   map[string]string{"foo": "stop pending", "bar": "stopped"},
+			expected: map[string]float64{"foo": 1, "bar": 0},
+		},
+	}
+
+	for i, scrape := range scrapes {
+		got := tracker.update(scrape.states)
+		if !reflect.DeepEqual(got, scrape.expected) {
+			t.Errorf("scrape %d: expected %v, got %v", i, scrape.expected, got)
+		}
+	}
+}
+
+func TestServiceCounts(t *testing.T) {
+	fields := serviceFields{
+		"w3svc":   {state: "running", startMode: "auto"},
+		"was":     {state: "running", startMode: "auto"},
+		"spooler": {state: "stopped", startMode: "auto"},
+		"fax":     {state: "stopped", startMode: "disabled"},
+	}
+
+	counts := serviceCounts(fields)
+	if len(counts) != len(allStates)*len(allStartModes) {
+		t.Errorf("expected %d combinations, got %d", len(allStates)*len(allStartModes), len(counts))
+	}
+
+	for k, want := range map[serviceModeFields]float64{
+		{"running", "auto"}:     2,
+		{"stopped", "auto"}:     1,
+		{"stopped", "disabled"}: 1,
+		{"paused", "manual"}:    0,
+	} {
+		if got := counts[k]; got != want {
+			t.Errorf("%v: expected %v, got %v", k, want, got)
+		}
+	}
+}
+
+func TestServiceStateUnexpected(t *testing.T) {
+	cases := []struct {
+		fields   serviceModeFields
+		expected bool
+	}{
+		{serviceModeFields{state: "running", startMode: "auto"}, false},
+		{serviceModeFields{state: "stopped", startMode: "auto"}, true},
+		{serviceModeFields{state: "paused", startMode: "auto"}, true},
+		{serviceModeFields{state: "start pending", startMode: "auto"}, false},
+		{serviceModeFields{state: "stopped", startMode: "disabled"}, false},
+		{serviceModeFields{state: "running", startMode: "disabled"}, true},
+		{serviceModeFields{state: "stop pending", startMode: "disabled"}, false},
+		{serviceModeFields{state: "stopped", startMode: "manual"}, false},
+		{serviceModeFields{state: "running", startMode: "manual"}, false},
+	}
+	for _, c := range cases {
+		if got := serviceStateUnexpected(c.fields); got != c.expected {
+			t.Errorf("%+v: expected %t, got %t", c.fields, c.expected, got)
+		}
+	}
+}
+
+func TestServiceDisplayInfo(t *testing.T) {
+	c := &serviceCollector{
+		Information: prometheus.NewDesc("info", "", []string{"name", "display_name", "run_as"}, nil),
+		ProcessID:   prometheus.NewDesc("process_id", "", []string{"name"}, nil),
+		DisplayInfo: prometheus.NewDesc("display_info", "", []string{"name", "display_name"}, nil),
+	}
+
+	for _, enabled := range []bool{false, true} {
+		c.displayMetric = enabled
+		ch := make(chan prometheus.Metric, 10)
+		c.collectInfo(ch, "w3svc", "World Wide Web Publishing Service", 0, "")
+		close(ch)
+
+		found := false
+		for m := range ch {
+			found = found || m.Desc() == c.DisplayInfo
+		}
+		if found != enabled {
+			t.Errorf("displayMetric=%t: expected display info %t, got %t", enabled, enabled, found)
+		}
+	}
+}
+
+// BenchmarkServiceStatusPerService queries the status of every service with its
+// own handle, as the api query mode used to: 3 calls per service (open, query
+// and close) plus the listing. Compare with BenchmarkServiceStatusEnum, which
+// gets the same statuses from the listing alone.
+func BenchmarkServiceStatusPerService(b *testing.B) {
+	m, err := mgr.Connect()
+	if err != nil {
+		b.Skip(err)
+	}
+	defer m.Disconnect()
+
+	for i := 0; i < b.N; i++ {
+		names, err := m.ListServices()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, name := range names {
+			s, err := m.OpenService(name)
+			if err != nil {
+				continue
+			}
+			_, _ = queryServiceStatus(s.Handle)
+			_ = s.Close()
+		}
+	}
+}
+
+func BenchmarkServiceStatusEnum(b *testing.B) {
+	m, err := mgr.Connect()
+	if err != nil {
+		b.Skip(err)
+	}
+	defer m.Disconnect()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := enumServices(m.Handle, windows.SERVICE_STATE_ALL); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestServiceCollectErrors(t *testing.T) {
+	e := newServiceCollectErrors()
+	if got := e.counts(); !reflect.DeepEqual(got, map[string]float64{"open": 0, "config": 0}) {
+		t.Errorf("expected all stages at 0, got %v", got)
+	}
+
+	e.inc(serviceStageOpen)
+	e.inc(serviceStageOpen)
+	e.inc(serviceStageConfig)
+	counts := e.counts()
+	if !reflect.DeepEqual(counts, map[string]float64{"open": 2, "config": 1}) {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	counts["open"] = 10
+	if got := e.counts()["open"]; got != 2 {
+		t.Errorf("expected counts to return a copy, got %v", got)
+	}
+}
@@ -0,0 +1,86 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("smb_client", newSMBClientCollector, "SMB Client Shares")
+}
+
+var smbClientLog = log.With("collector", "smb_client")
+
+// A SMBClientCollector is a Prometheus collector for Perflib SMB Client Shares metrics
+type SMBClientCollector struct {
+	ReadBytesTotal  *prometheus.Desc
+	WriteBytesTotal *prometheus.Desc
+}
+
+func newSMBClientCollector() (Collector, error) {
+	const subsystem = "smb_client"
+
+	return &SMBClientCollector{
+		ReadBytesTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "read_bytes_total"),
+			"Number of bytes read from the share (SMB Client Shares.Read Bytes/sec)",
+			[]string{"share"},
+			nil,
+		),
+		WriteBytesTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "write_bytes_total"),
+			"Number of bytes written to the share (SMB Client Shares.Write Bytes/sec)",
+			[]string{"share"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *SMBClientCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		smbClientLog.Error("failed collecting smb_client metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib "SMB Client Shares"
+type smbClientShare struct {
+	Name string
+
+	ReadBytesPerSec  float64 `perflib:"Read Bytes/sec"`
+	WriteBytesPerSec float64 `perflib:"Write Bytes/sec"`
+}
+
+func (c *SMBClientCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []smbClientShare
+	if err := unmarshalObject(ctx.perfObjects["SMB Client Shares"], &dst); err != nil {
+		return nil, err
+	}
+
+	for _, share := range dst {
+		if share.Name == "_Total" {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.ReadBytesTotal,
+			prometheus.CounterValue,
+			share.ReadBytesPerSec,
+			share.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.WriteBytesTotal,
+			prometheus.CounterValue,
+			share.WriteBytesPerSec,
+			share.Name,
+		)
+	}
+
+	return nil, nil
+}
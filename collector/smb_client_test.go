@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkSMBClientCollector(b *testing.B) {
+	benchmarkCollector(b, "smb_client", newSMBClientCollector)
+}
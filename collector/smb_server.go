@@ -0,0 +1,86 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("smb_server", NewSMBServerCollector)
+}
+
+var smbServerLog = log.With("collector", "smb_server")
+
+const smbNamespace = `root\Microsoft\Windows\SMB`
+
+// A SMBServerCollector is a Prometheus collector for WMI MSFT_SmbSession metrics
+type SMBServerCollector struct {
+	Sessions  *prometheus.Desc
+	OpenFiles *prometheus.Desc
+}
+
+// NewSMBServerCollector ...
+func NewSMBServerCollector() (Collector, error) {
+	const subsystem = "smb_server"
+
+	return &SMBServerCollector{
+		Sessions: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "sessions"),
+			"Number of sessions established on the SMB server",
+			nil,
+			nil,
+		),
+		OpenFiles: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "open_files"),
+			"Number of files opened on the SMB server, over all sessions (NumOpens)",
+			nil,
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *SMBServerCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		smbServerLog.Error("failed collecting smb_server metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// MSFT_SmbSession docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/smb/msft-smbsession
+type MSFT_SmbSession struct {
+	SessionId uint64
+	NumOpens  uint64
+}
+
+func (c *SMBServerCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []MSFT_SmbSession
+	q := queryAll(&dst)
+	if err := wmiQueryNamespace(q, &dst, smbNamespace); err != nil {
+		return nil, err
+	}
+
+	var openFiles uint64
+	for _, session := range dst {
+		openFiles += session.NumOpens
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.Sessions,
+		prometheus.GaugeValue,
+		float64(len(dst)),
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		c.OpenFiles,
+		prometheus.GaugeValue,
+		float64(openFiles),
+	)
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkSMBServerCollector(b *testing.B) {
+	benchmarkCollector(b, "smb_server", NewSMBServerCollector)
+}
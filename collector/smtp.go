@@ -14,6 +14,8 @@ func init() {
 	registerCollector("smtp", NewSMTPCollector, "SMTP Server")
 }
 
+var smtpLog = log.With("collector", "smtp")
+
 var (
 	serverWhitelist = kingpin.Flag("collector.smtp.server-whitelist", "Regexp of virtual servers to whitelist. Server name must both match whitelist and not match blacklist to be included.").Default(".+").String()
 	serverBlacklist = kingpin.Flag("collector.smtp.server-blacklist", "Regexp of virtual servers to blacklist. Server name must both match whitelist and not match blacklist to be included.").String()
@@ -68,7 +70,7 @@ type SMTPCollector struct {
 }
 
 func NewSMTPCollector() (Collector, error) {
-	log.Info("smtp collector is in an experimental state! Metrics for this collector have not been tested.")
+	smtpLog.Info("smtp collector is in an experimental state! Metrics for this collector have not been tested.")
 	const subsystem = "smtp"
 
 	return &SMTPCollector{
@@ -334,7 +336,7 @@ func NewSMTPCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *SMTPCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting smtp metrics:", desc, err)
+		smtpLog.Error("failed collecting smtp metrics:", desc, err)
 		return err
 	}
 	return nil
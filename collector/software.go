@@ -0,0 +1,164 @@
+// +build windows
+
+package collector
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+func init() {
+	registerCollector("software", newSoftwareCollector)
+}
+
+var softwareLog = log.With("collector", "software")
+
+var (
+	softwareInclude = kingpin.Flag(
+		"collector.software.include",
+		"Regexp of software names to include. Name must both match include and not match exclude to be included.",
+	).Default(".+").String()
+	softwareExclude = kingpin.Flag(
+		"collector.software.exclude",
+		"Regexp of software names to exclude. Name must both match include and not match exclude to be included.",
+	).Default("").String()
+)
+
+// softwareUninstallKey lists the software installed for all users, as shown
+// in Programs and Features. Win32_Product is deliberately not used: querying
+// it is slow, and triggers a consistency check of every MSI package.
+const softwareUninstallKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`
+
+// A SoftwareCollector is a Prometheus collector for the installed software,
+// read from the uninstall registry keys
+type SoftwareCollector struct {
+	Installed *prometheus.Desc
+
+	includePattern *regexp.Regexp
+	excludePattern *regexp.Regexp
+}
+
+func newSoftwareCollector() (Collector, error) {
+	const subsystem = "software"
+
+	includePattern, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", *softwareInclude))
+	if err != nil {
+		return nil, fmt.Errorf("invalid collector.software.include pattern: %v", err)
+	}
+	excludePattern, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", *softwareExclude))
+	if err != nil {
+		return nil, fmt.Errorf("invalid collector.software.exclude pattern: %v", err)
+	}
+
+	return &SoftwareCollector{
+		Installed: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "installed"),
+			"A metric with a constant '1' value labeled with the name, version and publisher of installed software",
+			[]string{"name", "version", "publisher"},
+			nil,
+		),
+		includePattern: includePattern,
+		excludePattern: excludePattern,
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *SoftwareCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		softwareLog.Error("failed collecting software metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+type installedSoftware struct {
+	name      string
+	version   string
+	publisher string
+}
+
+func (c *SoftwareCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	// 32-bit software is registered in a separate view of the registry on
+	// 64-bit Windows.
+	var entries []installedSoftware
+	for _, view := range []uint32{registry.WOW64_64KEY, registry.WOW64_32KEY} {
+		e, err := readUninstallKey(view)
+		if err != nil {
+			return c.Installed, err
+		}
+		entries = append(entries, e...)
+	}
+
+	for _, s := range filterSoftware(entries, c.includePattern, c.excludePattern) {
+		ch <- prometheus.MustNewConstMetric(
+			c.Installed,
+			prometheus.GaugeValue,
+			1.0,
+			s.name,
+			s.version,
+			s.publisher,
+		)
+	}
+
+	return nil, nil
+}
+
+// readUninstallKey reads the software registered in a view of the uninstall
+// key. As in Programs and Features, system components and updates of other
+// software are left out.
+func readUninstallKey(view uint32) ([]installedSoftware, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, softwareUninstallKey, registry.ENUMERATE_SUB_KEYS|view)
+	if err != nil {
+		return nil, err
+	}
+	defer k.Close()
+
+	names, err := k.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]installedSoftware, 0, len(names))
+	for _, name := range names {
+		sk, err := registry.OpenKey(k, name, registry.QUERY_VALUE|view)
+		if err != nil {
+			softwareLog.Debugf("Couldn't open uninstall key %s: %v", name, err)
+			continue
+		}
+		displayName, _, _ := sk.GetStringValue("DisplayName")
+		systemComponent, _, _ := sk.GetIntegerValue("SystemComponent")
+		parentKeyName, _, _ := sk.GetStringValue("ParentKeyName")
+		if displayName == "" || systemComponent == 1 || parentKeyName != "" {
+			sk.Close()
+			continue
+		}
+		version, _, _ := sk.GetStringValue("DisplayVersion")
+		publisher, _, _ := sk.GetStringValue("Publisher")
+		sk.Close()
+
+		entries = append(entries, installedSoftware{name: displayName, version: version, publisher: publisher})
+	}
+	return entries, nil
+}
+
+// filterSoftware returns the entries whose name matches include and doesn't
+// match exclude, without duplicates, as software may be registered in both
+// registry views.
+func filterSoftware(entries []installedSoftware, include, exclude *regexp.Regexp) []installedSoftware {
+	seen := make(map[installedSoftware]bool, len(entries))
+	filtered := make([]installedSoftware, 0, len(entries))
+	for _, s := range entries {
+		if seen[s] || !include.MatchString(s.name) || exclude.MatchString(s.name) {
+			continue
+		}
+		seen[s] = true
+		filtered = append(filtered, s)
+	}
+	return filtered
+}
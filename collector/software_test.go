@@ -0,0 +1,33 @@
+package collector
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestFilterSoftware(t *testing.T) {
+	entries := []installedSoftware{
+		{name: "7-Zip 19.00 (x64)", version: "19.00", publisher: "Igor Pavlov"},
+		{name: "Microsoft Visual C++ 2019 X64 Runtime", version: "14.28.29913", publisher: "Microsoft Corporation"},
+		{name: "Microsoft Visual C++ 2019 X86 Runtime", version: "14.28.29913", publisher: "Microsoft Corporation"},
+		// Registered in both registry views.
+		{name: "7-Zip 19.00 (x64)", version: "19.00", publisher: "Igor Pavlov"},
+	}
+
+	got := filterSoftware(entries, regexp.MustCompile("^(?:.+)$"), regexp.MustCompile("^(?:.*X86.*)$"))
+	want := []installedSoftware{entries[0], entries[1]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterSoftware() = %v, want %v", got, want)
+	}
+
+	got = filterSoftware(entries, regexp.MustCompile("^(?:Microsoft.*)$"), regexp.MustCompile("^(?:)$"))
+	want = []installedSoftware{entries[1], entries[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterSoftware() = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkSoftwareCollector(b *testing.B) {
+	benchmarkCollector(b, "software", newSoftwareCollector)
+}
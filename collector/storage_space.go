@@ -0,0 +1,181 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("storage_space", newStorageSpaceCollector)
+}
+
+var storageSpaceLog = log.With("collector", "storage_space")
+
+// A StorageSpaceCollector is a Prometheus collector for WMI MSFT_StoragePool
+// and MSFT_VirtualDisk metrics
+type StorageSpaceCollector struct {
+	PoolInfo                *prometheus.Desc
+	PoolHealthStatus        *prometheus.Desc
+	PoolCapacityBytes       *prometheus.Desc
+	PoolAllocatedBytes      *prometheus.Desc
+	VirtualDiskInfo         *prometheus.Desc
+	VirtualDiskHealthStatus *prometheus.Desc
+}
+
+func newStorageSpaceCollector() (Collector, error) {
+	const subsystem = "storage_space"
+
+	return &StorageSpaceCollector{
+		PoolInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "pool_info"),
+			"A metric with a constant '1' value labeled with the unique ID and friendly name of the storage pool",
+			[]string{"pool", "name"},
+			nil,
+		),
+		PoolHealthStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "pool_health_status"),
+			"Health of the storage pool: 0 healthy, 1 warning, 2 unhealthy, 5 unknown (HealthStatus)",
+			[]string{"pool"},
+			nil,
+		),
+		PoolCapacityBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "pool_capacity_bytes"),
+			"Total capacity of the storage pool (Size)",
+			[]string{"pool"},
+			nil,
+		),
+		PoolAllocatedBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "pool_allocated_bytes"),
+			"Capacity of the storage pool allocated to virtual disks (AllocatedSize)",
+			[]string{"pool"},
+			nil,
+		),
+		VirtualDiskInfo: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "virtual_disk_info"),
+			"A metric with a constant '1' value labeled with the unique ID and friendly name of the virtual disk",
+			[]string{"disk", "name"},
+			nil,
+		),
+		VirtualDiskHealthStatus: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "virtual_disk_health_status"),
+			"Health of the virtual disk: 0 healthy, 1 warning, 2 unhealthy, 5 unknown (HealthStatus)",
+			[]string{"disk"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *StorageSpaceCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collectPools(ch); err != nil {
+		storageSpaceLog.Error("failed collecting storage_space pool metrics:", desc, err)
+		return err
+	}
+	if desc, err := c.collectVirtualDisks(ch); err != nil {
+		storageSpaceLog.Error("failed collecting storage_space virtual disk metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// MSFT_StoragePool docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/stormgmt/msft-storagepool
+//
+// The friendly names of pools and virtual disks aren't unique, their unique
+// IDs label the metrics instead.
+type MSFT_StoragePool struct {
+	UniqueId      string
+	FriendlyName  string
+	IsPrimordial  bool
+	HealthStatus  uint16
+	Size          uint64
+	AllocatedSize uint64
+}
+
+func (c *StorageSpaceCollector) collectPools(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []MSFT_StoragePool
+	q := queryAll(&dst)
+	if err := wmiQueryNamespace(q, &dst, storageNamespace); err != nil {
+		if isWMINotFoundError(err) {
+			storageSpaceLog.Debugf("Storage WMI namespace not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return c.PoolHealthStatus, err
+	}
+
+	for _, pool := range dst {
+		// The primordial pools hold the disks which aren't part of any
+		// storage pool yet.
+		if pool.IsPrimordial {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.PoolInfo,
+			prometheus.GaugeValue,
+			1.0,
+			pool.UniqueId,
+			pool.FriendlyName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.PoolHealthStatus,
+			prometheus.GaugeValue,
+			float64(pool.HealthStatus),
+			pool.UniqueId,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.PoolCapacityBytes,
+			prometheus.GaugeValue,
+			float64(pool.Size),
+			pool.UniqueId,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.PoolAllocatedBytes,
+			prometheus.GaugeValue,
+			float64(pool.AllocatedSize),
+			pool.UniqueId,
+		)
+	}
+
+	return nil, nil
+}
+
+// MSFT_VirtualDisk docs:
+// - https://docs.microsoft.com/en-us/previous-versions/windows/desktop/stormgmt/msft-virtualdisk
+type MSFT_VirtualDisk struct {
+	UniqueId     string
+	FriendlyName string
+	HealthStatus uint16
+}
+
+func (c *StorageSpaceCollector) collectVirtualDisks(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []MSFT_VirtualDisk
+	q := queryAll(&dst)
+	if err := wmiQueryNamespace(q, &dst, storageNamespace); err != nil {
+		if isWMINotFoundError(err) {
+			storageSpaceLog.Debugf("Storage WMI namespace not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return c.VirtualDiskHealthStatus, err
+	}
+
+	for _, disk := range dst {
+		ch <- prometheus.MustNewConstMetric(
+			c.VirtualDiskInfo,
+			prometheus.GaugeValue,
+			1.0,
+			disk.UniqueId,
+			disk.FriendlyName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.VirtualDiskHealthStatus,
+			prometheus.GaugeValue,
+			float64(disk.HealthStatus),
+			disk.UniqueId,
+		)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkStorageSpaceCollector(b *testing.B) {
+	benchmarkCollector(b, "storage_space", newStorageSpaceCollector)
+}
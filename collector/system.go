@@ -11,6 +11,8 @@ func init() {
 	registerCollector("system", NewSystemCollector, "System")
 }
 
+var systemLog = log.With("collector", "system")
+
 // A SystemCollector is a Prometheus collector for WMI metrics
 type SystemCollector struct {
 	ContextSwitchesTotal     *prometheus.Desc
@@ -69,7 +71,7 @@ func NewSystemCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *SystemCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting system metrics:", desc, err)
+		systemLog.Error("failed collecting system metrics:", desc, err)
 		return err
 	}
 	return nil
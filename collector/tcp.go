@@ -11,6 +11,8 @@ func init() {
 	registerCollector("tcp", NewTCPCollector, "TCPv4", "TCPv6")
 }
 
+var tcpLog = log.With("collector", "tcp")
+
 // A TCPCollector is a Prometheus collector for WMI Win32_PerfRawData_Tcpip_TCPv{4,6} metrics
 type TCPCollector struct {
 	ConnectionFailures         *prometheus.Desc
@@ -90,7 +92,7 @@ func NewTCPCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *TCPCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting tcp metrics:", desc, err)
+		tcpLog.Error("failed collecting tcp metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -17,6 +16,8 @@ func init() {
 	registerCollector("terminal_services", NewTerminalServicesCollector, "Terminal Services", "Terminal Services Session", "Remote Desktop Connection Broker Counterset")
 }
 
+var terminalServicesLog = log.With("collector", "terminal_services")
+
 var (
 	connectionBrokerEnabled = isConnectionBrokerServer()
 )
@@ -28,7 +29,7 @@ type Win32_ServerFeature struct {
 func isConnectionBrokerServer() bool {
 	var dst []Win32_ServerFeature
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
 		return false
 	}
 	for _, d := range dst {
@@ -36,7 +37,7 @@ func isConnectionBrokerServer() bool {
 			return true
 		}
 	}
-	log.Debug("host is not a connection broker skipping Connection Broker performance metrics.")
+	terminalServicesLog.Debug("host is not a connection broker skipping Connection Broker performance metrics.")
 	return false
 }
 
@@ -177,18 +178,18 @@ func NewTerminalServicesCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *TerminalServicesCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collectTSSessionCount(ctx, ch); err != nil {
-		log.Error("failed collecting terminal services session count metrics:", desc, err)
+		terminalServicesLog.Error("failed collecting terminal services session count metrics:", desc, err)
 		return err
 	}
 	if desc, err := c.collectTSSessionCounters(ctx, ch); err != nil {
-		log.Error("failed collecting terminal services session count metrics:", desc, err)
+		terminalServicesLog.Error("failed collecting terminal services session count metrics:", desc, err)
 		return err
 	}
 
 	// only collect CollectionBrokerPerformance if host is a Connection Broker
 	if connectionBrokerEnabled {
 		if desc, err := c.collectCollectionBrokerPerformanceCounter(ctx, ch); err != nil {
-			log.Error("failed collecting Connection Broker performance metrics:", desc, err)
+			terminalServicesLog.Error("failed collecting Connection Broker performance metrics:", desc, err)
 			return err
 		}
 	}
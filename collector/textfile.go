@@ -39,13 +39,6 @@ var (
 		"collector.textfile.directory",
 		"Directory to read text files with metrics from.",
 	).Default("C:\\Program Files\\windows_exporter\\textfile_inputs").String()
-
-	mtimeDesc = prometheus.NewDesc(
-		prometheus.BuildFQName(Namespace, "textfile", "mtime_seconds"),
-		"Unixtime mtime of textfiles successfully read.",
-		[]string{"file"},
-		nil,
-	)
 )
 
 type textFileCollector struct {
@@ -58,6 +51,8 @@ func init() {
 	registerCollector("textfile", NewTextFileCollector)
 }
 
+var textfileLog = log.With("collector", "textfile")
+
 // NewTextFileCollector returns a new Collector exposing metrics read from files
 // in the given textfile directory.
 func NewTextFileCollector() (Collector, error) {
@@ -107,7 +102,7 @@ func convertMetricFamily(metricFamily *dto.MetricFamily, ch chan<- prometheus.Me
 
 	for _, metric := range metricFamily.Metric {
 		if metric.TimestampMs != nil {
-			log.Warnf("Ignoring unsupported custom timestamp on textfile collector metric %v", metric)
+			textfileLog.Warnf("Ignoring unsupported custom timestamp on textfile collector metric %v", metric)
 		}
 
 		labels := metric.GetLabel()
@@ -177,7 +172,7 @@ func convertMetricFamily(metricFamily *dto.MetricFamily, ch chan<- prometheus.Me
 				buckets, values...,
 			)
 		default:
-			log.Errorf("unknown metric type for file")
+			textfileLog.Errorf("unknown metric type for file")
 			continue
 		}
 		if metricType == dto.MetricType_GAUGE || metricType == dto.MetricType_COUNTER || metricType == dto.MetricType_UNTYPED {
@@ -203,6 +198,12 @@ func (c *textFileCollector) exportMTimes(mtimes map[string]time.Time, ch chan<-
 		}
 		sort.Strings(filenames)
 
+		mtimeDesc := prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, "textfile", "mtime_seconds"),
+			"Unixtime mtime of textfiles successfully read.",
+			[]string{"file"},
+			nil,
+		)
 		for _, filename := range filenames {
 			mtime := float64(mtimes[filename].UnixNano() / 1e9)
 			if c.mtime != nil {
@@ -245,7 +246,7 @@ func (c *textFileCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Met
 	// Iterate over files and accumulate their metrics.
 	files, err := ioutil.ReadDir(c.path)
 	if err != nil && c.path != "" {
-		log.Errorf("Error reading textfile collector directory %q: %s", c.path, err)
+		textfileLog.Errorf("Error reading textfile collector directory %q: %s", c.path, err)
 		error = 1.0
 	}
 
@@ -259,34 +260,34 @@ fileLoop:
 			continue
 		}
 		path := filepath.Join(c.path, f.Name())
-		log.Debugf("Processing file %q", path)
+		textfileLog.Debugf("Processing file %q", path)
 		file, err := os.Open(path)
 		if err != nil {
-			log.Errorf("Error opening %q: %v", path, err)
+			textfileLog.Errorf("Error opening %q: %v", path, err)
 			error = 1.0
 			continue
 		}
 		var parser expfmt.TextParser
 		r, encoding := utfbom.Skip(carriageReturnFilteringReader{r: file})
 		if err = checkBOM(encoding); err != nil {
-			log.Errorf("Invalid file encoding detected in %s: %s - file must be UTF8", path, err.Error())
+			textfileLog.Errorf("Invalid file encoding detected in %s: %s - file must be UTF8", path, err.Error())
 			error = 1.0
 			continue
 		}
 		parsedFamilies, err := parser.TextToMetricFamilies(r)
 		closeErr := file.Close()
 		if closeErr != nil {
-			log.Warnf("Error closing file: %v", err)
+			textfileLog.Warnf("Error closing file: %v", err)
 		}
 		if err != nil {
-			log.Errorf("Error parsing %q: %v", path, err)
+			textfileLog.Errorf("Error parsing %q: %v", path, err)
 			error = 1.0
 			continue
 		}
 		for _, mf := range parsedFamilies {
 			for _, m := range mf.Metric {
 				if m.TimestampMs != nil {
-					log.Errorf("Textfile %q contains unsupported client-side timestamps, skipping entire file", path)
+					textfileLog.Errorf("Textfile %q contains unsupported client-side timestamps, skipping entire file", path)
 					error = 1.0
 					continue fileLoop
 				}
@@ -307,7 +308,7 @@ fileLoop:
 	}
 
 	if duplicateMetricEntry(metricFamilies) {
-		log.Errorf("Duplicate metrics detected in files")
+		textfileLog.Errorf("Duplicate metrics detected in files")
 		error = 1.0
 	} else {
 		for _, mf := range metricFamilies {
@@ -1,7 +1,6 @@
 package collector
 
 import (
-	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -10,11 +9,20 @@ func init() {
 	registerCollector("thermalzone", NewThermalZoneCollector)
 }
 
-// A thermalZoneCollector is a Prometheus collector for WMI Win32_PerfRawData_Counters_ThermalZoneInformation metrics
+var thermalzoneLog = log.With("collector", "thermalzone")
+
+// acpiNamespace is the WMI namespace of the ACPI classes
+const acpiNamespace = "root/wmi"
+
+// A thermalZoneCollector is a Prometheus collector for WMI Win32_PerfRawData_Counters_ThermalZoneInformation
+// and MSAcpi_ThermalZoneTemperature metrics
 type thermalZoneCollector struct {
 	PercentPassiveLimit *prometheus.Desc
 	Temperature         *prometheus.Desc
 	ThrottleReasons     *prometheus.Desc
+
+	ACPITemperature       *prometheus.Desc
+	ACPICriticalTripPoint *prometheus.Desc
 }
 
 // NewThermalZoneCollector ...
@@ -45,6 +53,22 @@ func NewThermalZoneCollector() (Collector, error) {
 			},
 			nil,
 		),
+		ACPITemperature: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "acpi_temperature_celsius"),
+			"Temperature of the ACPI thermal zone, in degrees Celsius (CurrentTemperature)",
+			[]string{
+				"name",
+			},
+			nil,
+		),
+		ACPICriticalTripPoint: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "critical_trip_point_celsius"),
+			"Temperature of the ACPI thermal zone at which the system shuts down, in degrees Celsius (CriticalTripPoint)",
+			[]string{
+				"name",
+			},
+			nil,
+		),
 	}, nil
 }
 
@@ -52,7 +76,11 @@ func NewThermalZoneCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *thermalZoneCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ch); err != nil {
-		log.Error("failed collecting thermalzone metrics:", desc, err)
+		thermalzoneLog.Error("failed collecting thermalzone metrics:", desc, err)
+		return err
+	}
+	if desc, err := c.collectACPI(ch); err != nil {
+		thermalzoneLog.Error("failed collecting thermalzone ACPI metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -71,16 +99,19 @@ type Win32_PerfRawData_Counters_ThermalZoneInformation struct {
 func (c *thermalZoneCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
 	var dst []Win32_PerfRawData_Counters_ThermalZoneInformation
 	q := queryAll(&dst)
-	if err := wmi.Query(q, &dst); err != nil {
+	if err := wmiQuery(q, &dst); err != nil {
+		if isWMINotFoundError(err) {
+			thermalzoneLog.Debugf("Thermal zone counters not available: %v. Skipping", err)
+			return nil, nil
+		}
 		return nil, err
 	}
 
 	for _, info := range dst {
-		//Divide by 10 and subtract 273.15 to convert decikelvin to celsius
 		ch <- prometheus.MustNewConstMetric(
 			c.Temperature,
 			prometheus.GaugeValue,
-			(float64(info.HighPrecisionTemperature)/10.0)-273.15,
+			decikelvinToCelsius(info.HighPrecisionTemperature),
 			info.Name,
 		)
 
@@ -101,3 +132,53 @@ func (c *thermalZoneCollector) collect(ch chan<- prometheus.Metric) (*prometheus
 
 	return nil, nil
 }
+
+// MSAcpi_ThermalZoneTemperature docs:
+// https://wutils.com/wmi/root/wmi/msacpi_thermalzonetemperature/
+type MSAcpi_ThermalZoneTemperature struct {
+	InstanceName string
+	Active       bool
+
+	CurrentTemperature uint32
+	CriticalTripPoint  uint32
+}
+
+func (c *thermalZoneCollector) collectACPI(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	var dst []MSAcpi_ThermalZoneTemperature
+	q := queryAll(&dst)
+	if err := wmiQueryNamespace(q, &dst, acpiNamespace); err != nil {
+		// Most VMs, and some hardware, don't expose ACPI thermal zones.
+		if isWMINotFoundError(err) {
+			thermalzoneLog.Debugf("ACPI thermal zones not available: %v. Skipping", err)
+			return nil, nil
+		}
+		return c.ACPITemperature, err
+	}
+
+	for _, zone := range dst {
+		if !zone.Active {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.ACPITemperature,
+			prometheus.GaugeValue,
+			decikelvinToCelsius(zone.CurrentTemperature),
+			zone.InstanceName,
+		)
+		if zone.CriticalTripPoint != 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.ACPICriticalTripPoint,
+				prometheus.GaugeValue,
+				decikelvinToCelsius(zone.CriticalTripPoint),
+				zone.InstanceName,
+			)
+		}
+	}
+
+	return nil, nil
+}
+
+// decikelvinToCelsius converts a temperature in tenths of Kelvin to Celsius
+func decikelvinToCelsius(t uint32) float64 {
+	return float64(t)/10.0 - 273.15
+}
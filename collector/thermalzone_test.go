@@ -1,9 +1,16 @@
 package collector
 
 import (
+	"math"
 	"testing"
 )
 
 func BenchmarkThermalZoneCollector(b *testing.B) {
 	benchmarkCollector(b, "thermalzone", NewThermalZoneCollector)
 }
+
+func TestDecikelvinToCelsius(t *testing.T) {
+	if got := decikelvinToCelsius(3232); math.Abs(got-50.05) > 1e-9 {
+		t.Errorf("expected 50.05, got %v", got)
+	}
+}
@@ -4,15 +4,19 @@ package collector
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/prometheus-community/windows_exporter/log"
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows/registry"
 )
 
 func init() {
 	registerCollector("time", newTimeCollector, "Windows Time Service")
 }
 
+var timeLog = log.With("collector", "time")
+
 // TimeCollector is a Prometheus collector for Perflib counter metrics
 type TimeCollector struct {
 	ClockFrequencyAdjustmentPPBTotal *prometheus.Desc
@@ -21,6 +25,7 @@ type TimeCollector struct {
 	NTPRoundtripDelay                *prometheus.Desc
 	NTPServerIncomingRequestsTotal   *prometheus.Desc
 	NTPServerOutgoingResponsesTotal  *prometheus.Desc
+	SyncSource                       *prometheus.Desc
 }
 
 func newTimeCollector() (Collector, error) {
@@ -67,6 +72,12 @@ func newTimeCollector() (Collector, error) {
 			nil,
 			nil,
 		),
+		SyncSource: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "sync_source"),
+			"A metric with a constant '1' value labeled by each NTP peer configured for W32Time, and the synchronization type",
+			[]string{"peer", "type"},
+			nil,
+		),
 	}, nil
 }
 
@@ -74,7 +85,7 @@ func newTimeCollector() (Collector, error) {
 // to the provided prometheus Metric channel.
 func (c *TimeCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
 	if desc, err := c.collect(ctx, ch); err != nil {
-		log.Error("failed collecting time metrics:", desc, err)
+		timeLog.Error("failed collecting time metrics:", desc, err)
 		return err
 	}
 	return nil
@@ -91,10 +102,20 @@ type windowsTime struct {
 }
 
 func (c *TimeCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	obj, ok := ctx.perfObjects["Windows Time Service"]
+	if !ok {
+		// The counters are only registered while W32Time is running.
+		timeLog.Debug("Windows Time Service counters are not available, W32Time may not be running. Skipping")
+		return nil, nil
+	}
+
 	var dst []windowsTime // Single-instance class, array is required but will have single entry.
-	if err := unmarshalObject(ctx.perfObjects["Windows Time Service"], &dst); err != nil {
+	if err := unmarshalObject(obj, &dst); err != nil {
 		return nil, err
 	}
+	if len(dst) == 0 {
+		return nil, nil
+	}
 
 	ch <- prometheus.MustNewConstMetric(
 		c.ClockFrequencyAdjustmentPPBTotal,
@@ -126,5 +147,58 @@ func (c *TimeCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric)
 		prometheus.CounterValue,
 		dst[0].NTPServerOutgoingResponsesTotal,
 	)
+
+	if desc, err := c.collectSyncSource(ch); err != nil {
+		return desc, err
+	}
+	return nil, nil
+}
+
+func (c *TimeCollector) collectSyncSource(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\W32Time\Parameters`, registry.QUERY_VALUE)
+	if err != nil {
+		return c.SyncSource, err
+	}
+	defer k.Close()
+
+	syncType, _, err := k.GetStringValue("Type")
+	if err != nil {
+		return c.SyncSource, err
+	}
+	ntpServer, _, err := k.GetStringValue("NtpServer")
+	if err != nil && err != registry.ErrNotExist {
+		return c.SyncSource, err
+	}
+
+	for _, peer := range parseNTPServer(ntpServer) {
+		ch <- prometheus.MustNewConstMetric(
+			c.SyncSource,
+			prometheus.GaugeValue,
+			1.0,
+			peer,
+			syncType,
+		)
+	}
 	return nil, nil
 }
+
+// parseNTPServer returns the peers of a W32Time NtpServer registry value, a
+// space-separated list of peers each optionally followed by a comma and a
+// hexadecimal flag value, e.g. "time.windows.com,0x9 pool.ntp.org,0x8". A
+// peer listed several times, e.g. with different flags, is only returned once.
+func parseNTPServer(value string) []string {
+	fields := strings.Fields(value)
+	peers := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if i := strings.IndexByte(field, ','); i >= 0 {
+			field = field[:i]
+		}
+		// Host names are case-insensitive.
+		if field != "" && !seen[strings.ToLower(field)] {
+			seen[strings.ToLower(field)] = true
+			peers = append(peers, field)
+		}
+	}
+	return peers
+}
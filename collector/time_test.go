@@ -1,9 +1,27 @@
 package collector
 
 import (
+	"reflect"
 	"testing"
 )
 
 func BenchmarkTimeCollector(b *testing.B) {
 	benchmarkCollector(b, "time", newTimeCollector)
 }
+
+func TestParseNTPServer(t *testing.T) {
+	cases := []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{}},
+		{"time.windows.com,0x9", []string{"time.windows.com"}},
+		{"0.pool.ntp.org,0x8  10.0.0.1 ,0x1", []string{"0.pool.ntp.org", "10.0.0.1"}},
+		{"time.windows.com,0x9 time.windows.com,0x8 Time.Windows.com", []string{"time.windows.com"}},
+	}
+	for _, c := range cases {
+		if got := parseNTPServer(c.value); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parseNTPServer(%q) = %q, want %q", c.value, got, c.want)
+		}
+	}
+}
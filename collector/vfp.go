@@ -0,0 +1,105 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	registerCollector("vfp", newVFPCollector, vfpInboundDropsObject, vfpOutboundDropsObject)
+}
+
+var vfpLog = log.With("collector", "vfp")
+
+const (
+	vfpInboundDropsObject  = "VFP Port Total Inbound Dropped Network Packets"
+	vfpOutboundDropsObject = "VFP Port Total Outbound Dropped Network Packets"
+)
+
+// A VFPCollector is a Prometheus collector for Perflib Virtual Filtering Platform metrics
+type VFPCollector struct {
+	PacketsDroppedTotal *prometheus.Desc
+}
+
+func newVFPCollector() (Collector, error) {
+	const subsystem = "vfp"
+
+	return &VFPCollector{
+		PacketsDroppedTotal: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "packets_dropped_total"),
+			"Number of packets dropped by the Virtual Filtering Platform on the switch port, by direction and reason",
+			[]string{"port", "direction", "reason"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *VFPCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ctx, ch); err != nil {
+		vfpLog.Error("failed collecting vfp metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+// Perflib "VFP Port Total Inbound Dropped Network Packets" and
+// "VFP Port Total Outbound Dropped Network Packets"
+type vfpPortDrops struct {
+	Name string
+
+	ACL         float64 `perflib:"Total Dropped ACL Packets"`
+	MACSpoofing float64 `perflib:"Total Dropped MAC Spoofing Packets"`
+	IPSpoofing  float64 `perflib:"Total Dropped IP Spoofing Packets"`
+	VLAN        float64 `perflib:"Total Dropped Filtered VLAN Packets"`
+	Other       float64 `perflib:"Total Dropped Other Packets"`
+}
+
+func (c *VFPCollector) collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	for direction, name := range map[string]string{
+		"inbound":  vfpInboundDropsObject,
+		"outbound": vfpOutboundDropsObject,
+	} {
+		obj, ok := ctx.perfObjects[name]
+		if !ok {
+			vfpLog.Debugf("%s counters are not available, VFP is probably not in use. Skipping", name)
+			continue
+		}
+
+		var dst []vfpPortDrops
+		if err := unmarshalObject(obj, &dst); err != nil {
+			return nil, err
+		}
+
+		for _, port := range dst {
+			if port.Name == "_Total" {
+				continue
+			}
+
+			for _, drop := range []struct {
+				reason string
+				value  float64
+			}{
+				{"acl", port.ACL},
+				{"mac_spoofing", port.MACSpoofing},
+				{"ip_spoofing", port.IPSpoofing},
+				{"filtered_vlan", port.VLAN},
+				{"other", port.Other},
+			} {
+				ch <- prometheus.MustNewConstMetric(
+					c.PacketsDroppedTotal,
+					prometheus.CounterValue,
+					drop.value,
+					port.Name,
+					direction,
+					drop.reason,
+				)
+			}
+		}
+	}
+
+	return nil, nil
+}
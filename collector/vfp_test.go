@@ -0,0 +1,9 @@
+package collector
+
+import (
+	"testing"
+)
+
+func BenchmarkVFPCollector(b *testing.B) {
+	benchmarkCollector(b, "vfp", newVFPCollector)
+}
@@ -0,0 +1,150 @@
+// +build windows
+
+package collector
+
+import (
+	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sys/windows"
+)
+
+func init() {
+	registerCollector("volume", NewVolumeCollector)
+}
+
+var volumeLog = log.With("collector", "volume")
+
+// A VolumeCollector is a Prometheus collector for the free space of every
+// volume, including those mounted as folders and without a drive letter
+type VolumeCollector struct {
+	FreeBytes *prometheus.Desc
+	SizeBytes *prometheus.Desc
+}
+
+// NewVolumeCollector ...
+func NewVolumeCollector() (Collector, error) {
+	const subsystem = "volume"
+
+	return &VolumeCollector{
+		FreeBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "free_bytes"),
+			"Free space on the volume, in bytes (GetDiskFreeSpaceEx.TotalNumberOfFreeBytes)",
+			[]string{"volume", "mount"},
+			nil,
+		),
+		SizeBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, subsystem, "size_bytes"),
+			"Total size of the volume, in bytes (GetDiskFreeSpaceEx.TotalNumberOfBytes)",
+			[]string{"volume", "mount"},
+			nil,
+		),
+	}, nil
+}
+
+// Collect sends the metric values for each metric
+// to the provided prometheus Metric channel.
+func (c *VolumeCollector) Collect(ctx *ScrapeContext, ch chan<- prometheus.Metric) error {
+	if desc, err := c.collect(ch); err != nil {
+		volumeLog.Error("failed collecting volume metrics:", desc, err)
+		return err
+	}
+	return nil
+}
+
+func (c *VolumeCollector) collect(ch chan<- prometheus.Metric) (*prometheus.Desc, error) {
+	volumes, err := listVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, volume := range volumes {
+		var free, size uint64
+		if err := windows.GetDiskFreeSpaceEx(windows.StringToUTF16Ptr(volume), nil, &size, &free); err != nil {
+			// Volumes without media, such as empty card readers, can't be queried.
+			volumeLog.Debugf("Failed to get free space of volume %s: %v", volume, err)
+			continue
+		}
+
+		mounts, err := volumeMountPoints(volume)
+		if err != nil {
+			volumeLog.Debugf("Failed to get mount points of volume %s: %v", volume, err)
+		}
+		if len(mounts) == 0 {
+			mounts = []string{""}
+		}
+
+		for _, mount := range mounts {
+			ch <- prometheus.MustNewConstMetric(
+				c.FreeBytes,
+				prometheus.GaugeValue,
+				float64(free),
+				volume, mount,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				c.SizeBytes,
+				prometheus.GaugeValue,
+				float64(size),
+				volume, mount,
+			)
+		}
+	}
+
+	return nil, nil
+}
+
+// listVolumes returns the GUID paths (\\?\Volume{...}\) of all volumes.
+func listVolumes() ([]string, error) {
+	buf := make([]uint16, windows.MAX_PATH)
+	handle, err := windows.FindFirstVolume(&buf[0], uint32(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+	defer windows.FindVolumeClose(handle)
+
+	var volumes []string
+	for {
+		volumes = append(volumes, windows.UTF16ToString(buf))
+		if err := windows.FindNextVolume(handle, &buf[0], uint32(len(buf))); err != nil {
+			if err == windows.ERROR_NO_MORE_FILES {
+				return volumes, nil
+			}
+			return nil, err
+		}
+	}
+}
+
+// volumeMountPoints returns the drive letters and folders the volume is
+// mounted on.
+func volumeMountPoints(volume string) ([]string, error) {
+	name := windows.StringToUTF16Ptr(volume)
+	size := uint32(windows.MAX_PATH)
+	for {
+		buf := make([]uint16, size)
+		err := windows.GetVolumePathNamesForVolumeName(name, &buf[0], size, &size)
+		if err == windows.ERROR_MORE_DATA {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return splitMultiSZ(buf), nil
+	}
+}
+
+// splitMultiSZ splits a sequence of null-terminated strings, terminated by an
+// empty string.
+func splitMultiSZ(buf []uint16) []string {
+	var strs []string
+	for start := 0; start < len(buf); {
+		end := start
+		for end < len(buf) && buf[end] != 0 {
+			end++
+		}
+		if end == start {
+			break
+		}
+		strs = append(strs, windows.UTF16ToString(buf[start:end]))
+		start = end + 1
+	}
+	return strs
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+func TestSplitMultiSZ(t *testing.T) {
+	multiSZ := func(strs ...string) []uint16 {
+		var buf []uint16
+		for _, s := range strs {
+			buf = append(buf, windows.StringToUTF16(s)...)
+		}
+		return append(buf, 0)
+	}
+
+	cases := []struct {
+		buf      []uint16
+		expected []string
+	}{
+		{multiSZ(), nil},
+		{multiSZ(`C:\`), []string{`C:\`}},
+		{multiSZ(`D:\`, `C:\mnt\data\`), []string{`D:\`, `C:\mnt\data\`}},
+		{append(multiSZ(`E:\`), 0, 0), []string{`E:\`}},
+	}
+	for _, c := range cases {
+		if got := splitMultiSZ(c.buf); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("expected %q, got %q", c.expected, got)
+		}
+	}
+}
+
+func BenchmarkVolumeCollector(b *testing.B) {
+	benchmarkCollector(b, "volume", NewVolumeCollector)
+}
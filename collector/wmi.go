@@ -2,11 +2,133 @@ package collector
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/StackExchange/wmi"
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
 	"github.com/prometheus-community/windows_exporter/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/alecthomas/kingpin.v2"
 )
 
+const (
+	// Namespace queried by wmiQuery, holding the core Win32_* classes.
+	wmiDefaultNamespace = `root\cimv2`
+
+	// Initial delay between WMI query retries. Doubled after each attempt.
+	wmiRetryBaseDelay = 100 * time.Millisecond
+
+	// HRESULT values returned by WMI/DCOM on a busy host, which are worth
+	// retrying. Anything else (invalid query, access denied, ...) is permanent.
+	wbemECallCancelled       = 0x80041032
+	wbemEShuttingDown        = 0x80041033
+	rpcECallRejected         = 0x80010001
+	rpcEServerCallRetryLater = 0x8001010A
+	rpcSServerTooBusy        = 0x800706BB
+	dispEException           = 0x80020009
+
+	// HRESULT values returned when the queried namespace or class doesn't
+	// exist, typically because the feature providing it isn't installed, or
+	// when its provider doesn't support the host, e.g. ACPI classes on VMs.
+	wbemEInvalidNamespace = 0x8004100E
+	wbemEInvalidClass     = 0x80041010
+	wbemENotFound         = 0x80041002
+	wbemENotSupported     = 0x8004100C
+
+	// HRESULT values returned when the account running the exporter isn't
+	// allowed to query the namespace, e.g. without administrator rights.
+	wbemEAccessDenied = 0x80041003
+	eAccessDenied     = 0x80070005
+
+	// oleSFalse is returned by CoInitializeEx when COM was already
+	// initialized on the thread.
+	oleSFalse = 0x00000001
+)
+
+var (
+	wmiMaxRetries = kingpin.Flag(
+		"collector.wmi.max-retries",
+		"Number of times a WMI query is retried after a transient failure. 0 to disable.",
+	).Default("2").Int()
+	wmiRemoteHost = kingpin.Flag(
+		"collector.wmi.remote-host",
+		"Host to run the WMI queries of the collectors against, instead of the local machine. Collectors which don't only rely on WMI are disabled.",
+	).Default("").String()
+	wmiRemoteUser = kingpin.Flag(
+		"collector.wmi.remote-user",
+		"User to connect to collector.wmi.remote-host as, e.g. DOMAIN\\user. The account of the exporter is used if empty.",
+	).Default("").String()
+	wmiRemotePassword = kingpin.Flag(
+		"collector.wmi.remote-password",
+		"Password of collector.wmi.remote-user.",
+	).Default("").Envar("WINDOWS_EXPORTER_WMI_REMOTE_PASSWORD").String()
+	wmiWarnRowThreshold = kingpin.Flag(
+		"collector.wmi.warn-row-threshold",
+		"Log a warning, once per class, when a WMI query returns more rows than this. 0 to disable.",
+	).Default("5000").Int()
+
+	// WMIRetriesTotal counts WMI queries retried after a transient failure.
+	WMIRetriesTotal = newWMIRetriesTotal()
+	// WMIResultRows records the number of rows returned by the last query of
+	// each class.
+	WMIResultRows = newWMIResultRows()
+
+	wmiRowWarnings = &wmiRowWarner{warned: map[string]bool{}}
+)
+
+func newWMIRetriesTotal() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "exporter",
+			Name:      "wmi_retries_total",
+			Help:      "windows_exporter: Number of WMI queries retried after a transient failure.",
+		},
+		[]string{"class"},
+	)
+}
+
+func newWMIResultRows() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: "exporter",
+			Name:      "wmi_result_rows",
+			Help:      "windows_exporter: Number of rows returned by the last WMI query of a class.",
+		},
+		[]string{"class"},
+	)
+}
+
+// A wmiRowWarner tracks the classes for which a warning about the size of
+// the query results was logged, to only log it once.
+type wmiRowWarner struct {
+	mu     sync.Mutex
+	warned map[string]bool
+}
+
+// observe reports whether a warning should be logged for a query of class
+// which returned the given number of rows.
+func (w *wmiRowWarner) observe(class string, rows, threshold int) bool {
+	if threshold <= 0 || rows <= threshold {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.warned[class] {
+		return false
+	}
+	w.warned[class] = true
+	return true
+}
+
 func className(src interface{}) string {
 	s := reflect.Indirect(reflect.ValueOf(src))
 	t := s.Type()
@@ -16,6 +138,292 @@ func className(src interface{}) string {
 	return t.Name()
 }
 
+// wmiQuery runs the query against the default root\cimv2 namespace. See
+// wmiQueryNamespace.
+func wmiQuery(query string, dst interface{}) error {
+	return wmiQueryNamespace(query, dst, wmiDefaultNamespace)
+}
+
+// wmiQueryNamespace is a wrapper around wmi.Query, connecting to the remote
+// host if one is set, and retrying with exponential backoff when the query
+// fails with a transient error.
+func wmiQueryNamespace(query string, dst interface{}, namespace string) error {
+	class := className(dst)
+	delay := wmiRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := wmi.Query(query, dst, wmiConnectServerArgs(namespace)...)
+		if err == nil {
+			rows := reflect.Indirect(reflect.ValueOf(dst)).Len()
+			WMIResultRows.WithLabelValues(class).Set(float64(rows))
+			if wmiRowWarnings.observe(class, rows, *wmiWarnRowThreshold) {
+				log.Warnf("WMI query of %s returned %d rows, more than the %d of collector.wmi.warn-row-threshold. "+
+					"This makes scrapes slow and expensive, consider filtering the query, e.g. with the where-clause flag of the collector: %s",
+					class, rows, *wmiWarnRowThreshold, query)
+			}
+			return nil
+		}
+		if attempt >= *wmiMaxRetries || !isTransientWMIError(err) {
+			return err
+		}
+		log.Debugf("Transient error querying %s in %s, retrying in %s: %v", class, namespace, delay, err)
+		WMIRetriesTotal.WithLabelValues(class).Inc()
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// wmiConnectServerArgs returns the arguments of SWbemLocator.ConnectServer
+// connecting to namespace, on the remote host if one is set.
+func wmiConnectServerArgs(namespace string) []interface{} {
+	if *wmiRemoteHost == "" {
+		return []interface{}{nil, namespace}
+	}
+	return []interface{}{*wmiRemoteHost, namespace, *wmiRemoteUser, *wmiRemotePassword}
+}
+
+// oleQueryNamespace runs query in namespace through the scripting API,
+// connecting to the remote host if one is set, and calls fn with each object
+// of the result. Unlike wmiQueryNamespace, it gives access to the methods of
+// the objects, and tells NULL properties apart from zero values.
+func oleQueryNamespace(namespace string, query string, fn func(item *ole.IDispatch) error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED); err != nil {
+		if code := err.(*ole.OleError).Code(); code != ole.S_OK && code != oleSFalse {
+			return err
+		}
+	}
+	defer ole.CoUninitialize()
+
+	unknown, err := oleutil.CreateObject("WbemScripting.SWbemLocator")
+	if err != nil {
+		return err
+	}
+	defer unknown.Release()
+
+	locator, err := unknown.QueryInterface(ole.IID_IDispatch)
+	if err != nil {
+		return err
+	}
+	defer locator.Release()
+
+	serviceRaw, err := oleutil.CallMethod(locator, "ConnectServer", wmiConnectServerArgs(namespace)...)
+	if err != nil {
+		return err
+	}
+	defer serviceRaw.Clear()
+
+	resultRaw, err := oleutil.CallMethod(serviceRaw.ToIDispatch(), "ExecQuery", query)
+	if err != nil {
+		return err
+	}
+	defer resultRaw.Clear()
+
+	return oleutil.ForEach(resultRaw.ToIDispatch(), func(itemRaw *ole.VARIANT) error {
+		defer itemRaw.Clear()
+		return fn(itemRaw.ToIDispatch())
+	})
+}
+
+func oleString(disp *ole.IDispatch, property string) (string, error) {
+	v, err := oleutil.GetProperty(disp, property)
+	if err != nil {
+		return "", err
+	}
+	defer v.Clear()
+	// Null properties, e.g. the drive letter of unmounted volumes, have no
+	// value.
+	s, _ := v.Value().(string)
+	return s, nil
+}
+
+func oleUint32(disp *ole.IDispatch, property string) (uint32, error) {
+	v, err := oleutil.GetProperty(disp, property)
+	if err != nil {
+		return 0, err
+	}
+	defer v.Clear()
+	n, ok := variantUint32(v.Value())
+	if !ok {
+		return 0, fmt.Errorf("property %s has unexpected type %T", property, v.Value())
+	}
+	return n, nil
+}
+
+// variantUint32 converts the value of an integer property. Automation
+// returns uint32 properties as VT_I4, hence the signed types.
+func variantUint32(v interface{}) (uint32, bool) {
+	switch n := v.(type) {
+	case int32:
+		return uint32(n), true
+	case uint32:
+		return n, true
+	case int64:
+		return uint32(n), true
+	case uint64:
+		return uint32(n), true
+	case int16:
+		return uint32(n), true
+	case uint16:
+		return uint32(n), true
+	case uint8:
+		return uint32(n), true
+	}
+	return 0, false
+}
+
+// oleNullableUint64 returns the value of an unsigned integer property, or nil
+// when the property is NULL.
+func oleNullableUint64(disp *ole.IDispatch, property string) (*uint64, error) {
+	v, err := oleutil.GetProperty(disp, property)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Clear()
+	return variantNullableUint64(property, v.VT, v.Value())
+}
+
+// variantNullableUint64 converts the value of an unsigned integer property of
+// type vt, returning nil when it is NULL. Automation returns uint64
+// properties as strings.
+func variantNullableUint64(property string, vt ole.VT, value interface{}) (*uint64, error) {
+	if vt == ole.VT_NULL || vt == ole.VT_EMPTY {
+		return nil, nil
+	}
+	var n uint64
+	switch v := value.(type) {
+	case string:
+		var err error
+		if n, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("property %s has invalid value %q: %v", property, v, err)
+		}
+	case int64:
+		n = uint64(v)
+	case uint64:
+		n = v
+	default:
+		n32, ok := variantUint32(value)
+		if !ok {
+			return nil, fmt.Errorf("property %s has unexpected type %T", property, value)
+		}
+		n = uint64(n32)
+	}
+	return &n, nil
+}
+
+// remoteCollectors lists the collectors which only rely on WMI, and so can
+// query collector.wmi.remote-host. The others read performance counters or
+// call APIs which only work on the local machine.
+var remoteCollectors = map[string]bool{
+	"ad":                              true,
+	"bitlocker":                       true,
+	"cpu_info":                        true,
+	"defender":                        true,
+	"dns":                             true,
+	"fsrmquota":                       true,
+	"hotfix":                          true,
+	"hyperv":                          true,
+	"hyperv_vm":                       true,
+	"license":                         true,
+	"logon":                           true,
+	"mscluster":                       true,
+	"msmq":                            true,
+	"netframework_clrexceptions":      true,
+	"netframework_clrinterop":         true,
+	"netframework_clrjit":             true,
+	"netframework_clrloading":         true,
+	"netframework_clrlocksandthreads": true,
+	"netframework_clrmemory":          true,
+	"netframework_clrremoting":        true,
+	"netframework_clrsecurity":        true,
+	"physical_disk":                   true,
+	"printer":                         true,
+	"service":                         true,
+	"smb_server":                      true,
+	"storage_space":                   true,
+	"thermalzone":                     true,
+	"vmware":                          true,
+}
+
+// RemoteHost returns the host set with collector.wmi.remote-host, empty when
+// the collectors query the local machine.
+func RemoteHost() string {
+	return *wmiRemoteHost
+}
+
+// SplitRemoteCollectors splits names between the collectors which can query
+// a remote host, and the others.
+func SplitRemoteCollectors(names []string) (supported []string, unsupported []string) {
+	for _, name := range names {
+		if remoteCollectors[name] {
+			supported = append(supported, name)
+		} else {
+			unsupported = append(unsupported, name)
+		}
+	}
+	return supported, unsupported
+}
+
+// wmiErrorCode returns the HRESULT carried by err, if any.
+func wmiErrorCode(err error) (uint32, bool) {
+	oleErr, ok := err.(*ole.OleError)
+	if !ok {
+		return 0, false
+	}
+	code := uint32(oleErr.Code())
+	// Errors raised by the WMI provider are wrapped in an exception, with
+	// the actual HRESULT in its SCODE.
+	if code == dispEException {
+		if excepInfo, ok := oleErr.SubError().(ole.EXCEPINFO); ok {
+			code = excepInfo.SCODE()
+		}
+	}
+	return code, true
+}
+
+// isTransientWMIError reports whether err carries an HRESULT indicating the
+// WMI service was too busy to answer, rather than a problem with the query.
+func isTransientWMIError(err error) bool {
+	code, ok := wmiErrorCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case wbemECallCancelled, wbemEShuttingDown, rpcECallRejected, rpcEServerCallRetryLater, rpcSServerTooBusy:
+		return true
+	}
+	return false
+}
+
+// isWMINotFoundError reports whether err indicates the queried namespace or
+// class doesn't exist, or isn't supported, on this host.
+func isWMINotFoundError(err error) bool {
+	code, ok := wmiErrorCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case wbemEInvalidNamespace, wbemEInvalidClass, wbemENotFound, wbemENotSupported:
+		return true
+	}
+	return false
+}
+
+// isWMIAccessDeniedError reports whether err indicates the exporter isn't
+// allowed to query the namespace or class.
+func isWMIAccessDeniedError(err error) bool {
+	code, ok := wmiErrorCode(err)
+	if !ok {
+		return false
+	}
+	switch code {
+	case wbemEAccessDenied, eAccessDenied:
+		return true
+	}
+	return false
+}
+
 func queryAll(src interface{}) string {
 	var b bytes.Buffer
 	b.WriteString("SELECT * FROM ")
@@ -34,6 +442,27 @@ func queryAllForClass(src interface{}, class string) string {
 	return b.String()
 }
 
+// wqlStringEscaper escapes the characters which are special in WQL string
+// literals: the backslash escape character and the quotes.
+var wqlStringEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`)
+
+// wqlString returns s as a quoted WQL string literal, safe to embed in a
+// where-clause whatever it contains.
+func wqlString(s string) string {
+	return "'" + wqlStringEscaper.Replace(s) + "'"
+}
+
+// wqlEqualsAny returns a condition matching the instances whose property
+// equals one of the values, e.g. (Name = 'a' OR Name = 'b'). WQL has no IN
+// operator, so the values are OR-ed. values must not be empty.
+func wqlEqualsAny(property string, values []string) string {
+	conditions := make([]string, 0, len(values))
+	for _, value := range values {
+		conditions = append(conditions, property+" = "+wqlString(value))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
 func queryAllWhere(src interface{}, where string) string {
 	var b bytes.Buffer
 	b.WriteString("SELECT * FROM ")
@@ -1,7 +1,11 @@
 package collector
 
 import (
+	"errors"
+	"reflect"
 	"testing"
+
+	"github.com/go-ole/go-ole"
 )
 
 type fakeWmiClass struct {
@@ -113,3 +117,260 @@ func TestCreateQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestWQLString(t *testing.T) {
+	cases := map[string]string{
+		"W3SVC":            `'W3SVC'`,
+		`it's`:             `'it\'s'`,
+		`C:\Program Files`: `'C:\\Program Files'`,
+		`say "hi"`:         `'say \"hi\"'`,
+		`' OR Name <> '`:   `'\' OR Name <> \''`,
+		`\'`:               `'\\\''`,
+	}
+	for in, want := range cases {
+		if got := wqlString(in); got != want {
+			t.Errorf("wqlString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestWQLEqualsAny(t *testing.T) {
+	got := wqlEqualsAny("Name", []string{"W3SVC", `o'brien\svc`})
+	want := `(Name = 'W3SVC' OR Name = 'o\'brien\\svc')`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestIsTransientWMIError(t *testing.T) {
+	cases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "call cancelled",
+			err:      ole.NewError(wbemECallCancelled),
+			expected: true,
+		},
+		{
+			desc:     "call rejected",
+			err:      ole.NewError(rpcECallRejected),
+			expected: true,
+		},
+		{
+			desc:     "invalid query",
+			err:      ole.NewError(0x80041017),
+			expected: false,
+		},
+		{
+			desc:     "not supported",
+			err:      ole.NewError(wbemENotSupported),
+			expected: false,
+		},
+		{
+			desc:     "access denied",
+			err:      ole.NewError(0x80070005),
+			expected: false,
+		},
+		{
+			desc:     "non-OLE error",
+			err:      errors.New("some error"),
+			expected: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if got := isTransientWMIError(c.err); got != c.expected {
+				t.Errorf("Case %q failed: Expected %v, got %v", c.desc, c.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsWMINotFoundError(t *testing.T) {
+	cases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "invalid namespace",
+			err:      ole.NewError(wbemEInvalidNamespace),
+			expected: true,
+		},
+		{
+			desc:     "invalid class",
+			err:      ole.NewError(wbemEInvalidClass),
+			expected: true,
+		},
+		{
+			desc:     "not supported",
+			err:      ole.NewError(wbemENotSupported),
+			expected: true,
+		},
+		{
+			desc:     "call cancelled",
+			err:      ole.NewError(wbemECallCancelled),
+			expected: false,
+		},
+		{
+			desc:     "non-OLE error",
+			err:      errors.New("some error"),
+			expected: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if got := isWMINotFoundError(c.err); got != c.expected {
+				t.Errorf("Case %q failed: Expected %v, got %v", c.desc, c.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsWMIAccessDeniedError(t *testing.T) {
+	cases := []struct {
+		desc     string
+		err      error
+		expected bool
+	}{
+		{
+			desc:     "WMI access denied",
+			err:      ole.NewError(wbemEAccessDenied),
+			expected: true,
+		},
+		{
+			desc:     "DCOM access denied",
+			err:      ole.NewError(eAccessDenied),
+			expected: true,
+		},
+		{
+			desc:     "invalid namespace",
+			err:      ole.NewError(wbemEInvalidNamespace),
+			expected: false,
+		},
+		{
+			desc:     "non-OLE error",
+			err:      errors.New("some error"),
+			expected: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if got := isWMIAccessDeniedError(c.err); got != c.expected {
+				t.Errorf("Case %q failed: Expected %v, got %v", c.desc, c.expected, got)
+			}
+		})
+	}
+}
+
+func TestWMIConnectServerArgs(t *testing.T) {
+	defer func(host, user, password string) {
+		*wmiRemoteHost, *wmiRemoteUser, *wmiRemotePassword = host, user, password
+	}(*wmiRemoteHost, *wmiRemoteUser, *wmiRemotePassword)
+
+	*wmiRemoteHost, *wmiRemoteUser, *wmiRemotePassword = "", "", ""
+	if got, want := wmiConnectServerArgs(`root\cimv2`), []interface{}{nil, `root\cimv2`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("local: expected %v, got %v", want, got)
+	}
+
+	*wmiRemoteHost, *wmiRemoteUser, *wmiRemotePassword = "appliance01", `CORP\monitoring`, "secret"
+	if got, want := wmiConnectServerArgs(`root\MSCluster`), []interface{}{"appliance01", `root\MSCluster`, `CORP\monitoring`, "secret"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("remote: expected %v, got %v", want, got)
+	}
+}
+
+func TestSplitRemoteCollectors(t *testing.T) {
+	supported, unsupported := SplitRemoteCollectors([]string{"cpu", "service", "os", "hyperv_vm"})
+	if want := []string{"service", "hyperv_vm"}; !reflect.DeepEqual(supported, want) {
+		t.Errorf("expected supported %v, got %v", want, supported)
+	}
+	if want := []string{"cpu", "os"}; !reflect.DeepEqual(unsupported, want) {
+		t.Errorf("expected unsupported %v, got %v", want, unsupported)
+	}
+}
+
+func TestRemoteCollectorsAreRegistered(t *testing.T) {
+	for name := range remoteCollectors {
+		if _, ok := builders[name]; !ok {
+			t.Errorf("remote collector %q isn't registered", name)
+		}
+	}
+}
+
+func TestWMIRowWarner(t *testing.T) {
+	w := &wmiRowWarner{warned: map[string]bool{}}
+	cases := []struct {
+		class     string
+		rows      int
+		threshold int
+		want      bool
+	}{
+		{"Win32_Service", 100, 5000, false},
+		{"Win32_Service", 5000, 5000, false},
+		{"Win32_Service", 5001, 5000, true},
+		// Only warned once per class.
+		{"Win32_Service", 6000, 5000, false},
+		{"Win32_Process", 6000, 5000, true},
+		{"Win32_Thread", 6000, 0, false},
+	}
+	for _, tc := range cases {
+		if got := w.observe(tc.class, tc.rows, tc.threshold); got != tc.want {
+			t.Errorf("observe(%q, %d, %d) = %t, want %t", tc.class, tc.rows, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+func TestVariantUint32(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected uint32
+		ok       bool
+	}{
+		{int32(42), 42, true},
+		{uint32(42), 42, true},
+		{uint8(1), 1, true},
+		{int64(100), 100, true},
+		{"42", 0, false},
+		{nil, 0, false},
+	}
+	for _, c := range cases {
+		got, ok := variantUint32(c.value)
+		if got != c.expected || ok != c.ok {
+			t.Errorf("variantUint32(%#v) = %d, %t, expected %d, %t", c.value, got, ok, c.expected, c.ok)
+		}
+	}
+}
+
+func TestVariantNullableUint64(t *testing.T) {
+	cases := []struct {
+		vt       ole.VT
+		value    interface{}
+		expected *uint64
+		err      bool
+	}{
+		{ole.VT_NULL, nil, nil, false},
+		{ole.VT_EMPTY, nil, nil, false},
+		{ole.VT_UI1, uint8(0), uint64Ptr(0), false},
+		{ole.VT_UI1, uint8(12), uint64Ptr(12), false},
+		{ole.VT_I4, int32(7), uint64Ptr(7), false},
+		{ole.VT_BSTR, "18446744073709551615", uint64Ptr(18446744073709551615), false},
+		{ole.VT_BSTR, "many", nil, true},
+		{ole.VT_BOOL, true, nil, true},
+	}
+	for _, c := range cases {
+		got, err := variantNullableUint64("Wear", c.vt, c.value)
+		if (err != nil) != c.err {
+			t.Errorf("variantNullableUint64(%d, %#v): unexpected error %v", c.vt, c.value, err)
+			continue
+		}
+		if (got == nil) != (c.expected == nil) || (got != nil && *got != *c.expected) {
+			t.Errorf("variantNullableUint64(%d, %#v) = %v, expected %v", c.vt, c.value, got, c.expected)
+		}
+	}
+}
+
+func uint64Ptr(n uint64) *uint64 {
+	return &n
+}
@@ -80,5 +80,11 @@ func (c *Resolver) Bind(app *kingpin.Application, args []string) error {
 		c.setDefault(pc.SelectedCommand)
 	}
 
+	for name := range c.flags {
+		if app.GetFlag(name) == nil && (pc.SelectedCommand == nil || pc.SelectedCommand.GetFlag(name) == nil) {
+			log.Warnf("Ignoring unknown flag %q in configuration file", name)
+		}
+	}
+
 	return nil
 }
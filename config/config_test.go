@@ -0,0 +1,60 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// Values from the configuration file are used as flag defaults, overridden by
+// the command line.
+func TestResolverBind(t *testing.T) {
+	file, err := ioutil.TempFile("", "windows_exporter_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`---
+collectors:
+  enabled: cpu,service
+collector:
+  service:
+    services-where: Name='windows_exporter'
+telemetry:
+  addr: ":9183"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	app := kingpin.New("test", "")
+	enabled := app.Flag("collectors.enabled", "").Default("cpu").String()
+	where := app.Flag("collector.service.services-where", "").Default("").String()
+	addr := app.Flag("telemetry.addr", "").Default(":9182").String()
+
+	args := []string{"--telemetry.addr=:9184"}
+	resolver, err := NewResolver(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resolver.Bind(app, args); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if *enabled != "cpu,service" {
+		t.Errorf("expected collectors.enabled from configuration file, got %q", *enabled)
+	}
+	if *where != "Name='windows_exporter'" {
+		t.Errorf("expected collector.service.services-where from configuration file, got %q", *where)
+	}
+	if *addr != ":9184" {
+		t.Errorf("expected telemetry.addr from command line, got %q", *addr)
+	}
+}
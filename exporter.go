@@ -3,19 +3,26 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"golang.org/x/sys/windows/svc"
 
+	"github.com/Microsoft/go-winio"
 	"github.com/StackExchange/wmi"
 	"github.com/prometheus-community/windows_exporter/collector"
 	"github.com/prometheus-community/windows_exporter/config"
@@ -31,6 +38,15 @@ import (
 type windowsCollector struct {
 	maxScrapeDuration time.Duration
 	collectors        map[string]collector.Collector
+	// allCollectors is set when the scrape covers every enabled collector,
+	// i.e. no collect[] parameter filtered it.
+	allCollectors bool
+	// maxConcurrency bounds the number of collectors running at once, 0
+	// meaning unbounded.
+	maxConcurrency int
+	// done is closed when the scrape is cancelled, e.g. because the client
+	// went away or the exporter is shutting down.
+	done <-chan struct{}
 }
 
 // Same struct prometheus uses for their /version endpoint.
@@ -45,12 +61,27 @@ type prometheusVersion struct {
 }
 
 const (
-	defaultCollectors            = "cpu,cs,logical_disk,net,os,service,system,textfile"
+	defaultCollectors            = "cpu,cs,logical_disk,net,os,paging,service,system,textfile"
 	defaultCollectorsPlaceholder = "[defaults]"
 	serviceName                  = "windows_exporter"
 )
 
+// The exporter metrics are built by initExporterMetrics, once the metric
+// namespace is known.
 var (
+	scrapeDurationDesc *prometheus.Desc
+	scrapeSuccessDesc  *prometheus.Desc
+	scrapeTimeoutDesc  *prometheus.Desc
+	snapshotDuration   *prometheus.Desc
+	scrapesInFlight    prometheus.Gauge
+	scrapesTotal       prometheus.Counter
+
+	// ready is set to 1 once a scrape of all enabled collectors has succeeded
+	// within its timeout, and is reported by /-/ready.
+	ready int32
+)
+
+func initExporterMetrics() {
 	scrapeDurationDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(collector.Namespace, "exporter", "collector_duration_seconds"),
 		"windows_exporter: Duration of a collection.",
@@ -75,7 +106,23 @@ var (
 		nil,
 		nil,
 	)
-)
+	scrapesInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: collector.Namespace,
+			Subsystem: "exporter",
+			Name:      "scrapes_in_flight",
+			Help:      "windows_exporter: Number of scrapes currently being served.",
+		},
+	)
+	scrapesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: collector.Namespace,
+			Subsystem: "exporter",
+			Name:      "scrapes_total",
+			Help:      "windows_exporter: Total number of scrapes served.",
+		},
+	)
+}
 
 // Describe sends all the descriptors of the collectors included to
 // the provided channel.
@@ -131,9 +178,26 @@ func (coll windowsCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 	}()
 
+	// Collectors beyond maxConcurrency queue for a slot. Those still queued
+	// once the scrape timed out are skipped, and reported as timed out.
+	var slots chan struct{}
+	if coll.maxConcurrency > 0 {
+		slots = make(chan struct{}, coll.maxConcurrency)
+	}
 	for name, c := range coll.collectors {
 		go func(name string, c collector.Collector) {
 			defer wg.Done()
+			if slots != nil {
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				l.Lock()
+				timedOut := finished
+				l.Unlock()
+				if timedOut {
+					return
+				}
+			}
 			outcome := execute(name, c, scrapeContext, metricsBuffer)
 			l.Lock()
 			if !finished {
@@ -150,15 +214,19 @@ func (coll windowsCollector) Collect(ch chan<- prometheus.Metric) {
 		close(metricsBuffer)
 	}()
 
-	// Wait until either all collectors finish, or timeout expires
+	// Wait until either all collectors finish, timeout expires, or the scrape
+	// is cancelled. Collectors still running can't be interrupted, but their
+	// metrics are discarded, and queued collectors are skipped.
 	select {
 	case <-allDone:
 	case <-time.After(coll.maxScrapeDuration):
+	case <-coll.done:
 	}
 
 	l.Lock()
 	finished = true
 
+	allSucceeded := true
 	remainingCollectorNames := make([]string, 0)
 	for name, outcome := range collectorOutcomes {
 		var successValue, timeoutValue float64
@@ -168,6 +236,8 @@ func (coll windowsCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 		if outcome == success {
 			successValue = 1.0
+		} else {
+			allSucceeded = false
 		}
 
 		ch <- prometheus.MustNewConstMetric(
@@ -187,6 +257,9 @@ func (coll windowsCollector) Collect(ch chan<- prometheus.Metric) {
 	if len(remainingCollectorNames) > 0 {
 		log.Warn("Collection timed out, still waiting for ", remainingCollectorNames)
 	}
+	if allSucceeded && coll.allCollectors {
+		atomic.StoreInt32(&ready, 1)
+	}
 
 	l.Unlock()
 }
@@ -203,10 +276,10 @@ func execute(name string, c collector.Collector, ctx *collector.ScrapeContext, c
 	)
 
 	if err != nil {
-		log.Errorf("collector %s failed after %fs: %s", name, duration, err)
+		log.With("collector", name).Errorf("collector %s failed after %fs: %s", name, duration, err)
 		return failed
 	}
-	log.Debugf("collector %s succeeded after %fs.", name, duration)
+	log.With("collector", name).Debugf("collector %s succeeded after %fs.", name, duration)
 	return success
 }
 
@@ -215,7 +288,7 @@ func expandEnabledCollectors(enabled string) []string {
 	separated := strings.Split(expanded, ",")
 	unique := map[string]bool{}
 	for _, s := range separated {
-		if s != "" {
+		if s = strings.TrimSpace(s); s != "" {
 			unique[s] = true
 		}
 	}
@@ -227,20 +300,82 @@ func expandEnabledCollectors(enabled string) []string {
 }
 
 func loadCollectors(list string) (map[string]collector.Collector, error) {
+	names := expandEnabledCollectors(list)
+	if err := validateCollectorNames(names, collector.Collectors()); err != nil {
+		return nil, err
+	}
+	if host := collector.RemoteHost(); host != "" {
+		var unsupported []string
+		names, unsupported = collector.SplitRemoteCollectors(names)
+		if len(unsupported) > 0 {
+			log.Warnf("Collectors %s can't query the remote host %s, as they don't only rely on WMI, and are disabled", strings.Join(unsupported, ", "), host)
+		}
+	}
+	collectors, err := buildCollectors(names, collector.Build)
+	if err != nil {
+		return nil, err
+	}
+	if err := collector.CheckDescriptors(collectors); err != nil {
+		return nil, fmt.Errorf("inconsistent metric descriptors: %v", err)
+	}
+	return collectors, nil
+}
+
+// validateCollectorNames checks that every enabled collector is registered,
+// before any of them is built, and lists the valid names otherwise.
+func validateCollectorNames(names []string, available []string) error {
+	known := make(map[string]bool, len(available))
+	for _, name := range available {
+		known[name] = true
+	}
+	var unknown []string
+	for _, name := range names {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown collector(s) %s in --collectors.enabled, valid collectors are: %s", strings.Join(unknown, ", "), strings.Join(available, ", "))
+}
+
+// buildCollectors constructs every named collector. Rather than stopping at
+// the first failure, it reports all the collectors which failed to
+// initialize, so that misconfigurations can be fixed in one go.
+func buildCollectors(names []string, build func(string) (collector.Collector, error)) (map[string]collector.Collector, error) {
 	collectors := map[string]collector.Collector{}
-	enabled := expandEnabledCollectors(list)
+	var failures []string
 
-	for _, name := range enabled {
-		c, err := collector.Build(name)
+	for _, name := range names {
+		c, err := build(name)
 		if err != nil {
-			return nil, err
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
 		}
 		collectors[name] = c
 	}
 
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("%d collector(s) failed to initialize: %s", len(failures), strings.Join(failures, "; "))
+	}
 	return collectors, nil
 }
 
+// printDescriptors writes the name of each collector, sorted, followed by the
+// descriptors of the metrics it exposes.
+func printDescriptors(w io.Writer, collectors map[string]collector.Collector) {
+	names := keys(collectors)
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\n", name)
+		for _, desc := range collector.Describe(collectors[name]) {
+			fmt.Fprintf(w, "  %s\n", desc)
+		}
+	}
+}
+
 func initWbem() {
 	// This initialization prevents a memory leak on WMF 5+. See
 	// https://github.com/prometheus-community/windows_exporter/issues/77 and
@@ -260,15 +395,27 @@ func main() {
 			"config.file",
 			"YAML configuration file to use. Values set in this file will be overriden by CLI flags.",
 		).String()
-		webConfig     = webflag.AddFlags(kingpin.CommandLine)
-		listenAddress = kingpin.Flag(
+		webConfig       = webflag.AddFlags(kingpin.CommandLine)
+		listenAddresses = kingpin.Flag(
 			"telemetry.addr",
-			"host:port for exporter.",
-		).Default(":9182").String()
+			"host:port for exporter. May be repeated, or be a comma-separated list, to listen on several addresses.",
+		).Default(":9182").Envar("WINDOWS_EXPORTER_TELEMETRY_ADDR").Strings()
+		listenPipe = kingpin.Flag(
+			"web.listen-pipe",
+			"Windows named pipe to expose metrics on, e.g. \\\\.\\pipe\\windows_exporter. Served in addition to telemetry.addr, unless the latter is empty.",
+		).Default("").String()
+		listenPipeAllow = kingpin.Flag(
+			"web.listen-pipe-allow",
+			"Account allowed to scrape web.listen-pipe, in addition to LocalSystem and Administrators, as a name such as DOMAIN\\prometheus or a SID. May be repeated.",
+		).Strings()
 		metricsPath = kingpin.Flag(
 			"telemetry.path",
 			"URL path for surfacing collected metrics.",
 		).Default("/metrics").String()
+		disableCompression = kingpin.Flag(
+			"telemetry.disable-compression",
+			"If true, never compress the metrics responses. By default they are gzip-compressed for clients accepting it.",
+		).Bool()
 		maxRequests = kingpin.Flag(
 			"telemetry.max-requests",
 			"Maximum number of concurrent requests. 0 to disable.",
@@ -276,15 +423,31 @@ func main() {
 		enabledCollectors = kingpin.Flag(
 			"collectors.enabled",
 			"Comma-separated list of collectors to use. Use '[defaults]' as a placeholder for all the collectors enabled by default.").
-			Default(defaultCollectors).String()
+			Default(defaultCollectors).Envar("WINDOWS_EXPORTER_COLLECTORS_ENABLED").String()
 		printCollectors = kingpin.Flag(
 			"collectors.print",
 			"If true, print available collectors and exit.",
 		).Bool()
+		dryRun = kingpin.Flag(
+			"collectors.dry-run",
+			"If true, print the enabled collectors and the descriptors of the metrics they would expose, then exit.",
+		).Bool()
 		timeoutMargin = kingpin.Flag(
 			"scrape.timeout-margin",
 			"Seconds to subtract from the timeout allowed by the client. Tune to allow for overhead or high loads.",
 		).Default("0.5").Float64()
+		maxConcurrency = kingpin.Flag(
+			"collectors.max-concurrency",
+			"Maximum number of collectors running at once during a scrape. 0 to run all enabled collectors at once.",
+		).Default("0").Int()
+		shutdownGracePeriod = kingpin.Flag(
+			"web.shutdown-grace-period",
+			"Time to wait for in-flight scrapes to complete when shutting down, after which their connections are closed.",
+		).Default("10s").Duration()
+		metricsNamespace = kingpin.Flag(
+			"metrics.namespace",
+			"Prefix of the names of the metrics exposed by the collectors and the exporter.",
+		).Default(collector.Namespace).String()
 	)
 
 	log.AddFlags(kingpin.CommandLine)
@@ -308,15 +471,20 @@ func main() {
 		kingpin.Parse()
 	}
 
+	if err := collector.RegistrationError(); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+
+	// The namespace must be set before the collectors and exporter metrics
+	// are built, as their descriptors embed it.
+	if err := collector.SetNamespace(*metricsNamespace); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	initExporterMetrics()
+
 	if *printCollectors {
-		collectors := collector.Available()
-		collectorNames := make(sort.StringSlice, 0, len(collectors))
-		for _, n := range collectors {
-			collectorNames = append(collectorNames, n)
-		}
-		collectorNames.Sort()
 		fmt.Printf("Available collectors:\n")
-		for _, n := range collectorNames {
+		for _, n := range collector.Collectors() {
 			fmt.Printf(" - %s\n", n)
 		}
 		return
@@ -324,20 +492,38 @@ func main() {
 
 	initWbem()
 
+	if *dryRun {
+		collectors, err := loadCollectors(*enabledCollectors)
+		if err != nil {
+			log.Fatalf("Couldn't load collectors: %s", err)
+		}
+		printDescriptors(os.Stdout, collectors)
+		return
+	}
+
 	isInteractive, err := svc.IsAnInteractiveSession()
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	stopCh := make(chan bool)
+	stoppedCh := make(chan struct{})
+	serviceDone := make(chan struct{})
 	if !isInteractive {
 		go func() {
-			err = svc.Run(serviceName, &windowsExporterService{stopCh: stopCh})
+			defer close(serviceDone)
+			err = svc.Run(serviceName, &windowsExporterService{
+				stopCh:              stopCh,
+				stoppedCh:           stoppedCh,
+				shutdownGracePeriod: *shutdownGracePeriod,
+			})
 			if err != nil {
 				log.Errorf("Failed to start service: %v", err)
 			}
 		}()
 	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 
 	collectors, err := loadCollectors(*enabledCollectors)
 	if err != nil {
@@ -347,29 +533,25 @@ func main() {
 	log.Infof("Enabled collectors: %v", strings.Join(keys(collectors), ", "))
 
 	h := &metricsHandler{
-		timeoutMargin: *timeoutMargin,
+		timeoutMargin:      *timeoutMargin,
+		disableCompression: *disableCompression,
 		collectorFactory: func(timeout time.Duration, requestedCollectors []string) (error, *windowsCollector) {
-			filteredCollectors := make(map[string]collector.Collector)
-			// scrape all enabled collectors if no collector is requested
-			if len(requestedCollectors) == 0 {
-				filteredCollectors = collectors
-			}
-			for _, name := range requestedCollectors {
-				col, exists := collectors[name]
-				if !exists {
-					return fmt.Errorf("unavailable collector: %s", name), nil
-				}
-				filteredCollectors[name] = col
+			filteredCollectors, err := filterCollectors(collectors, requestedCollectors)
+			if err != nil {
+				return err, nil
 			}
 			return nil, &windowsCollector{
 				collectors:        filteredCollectors,
 				maxScrapeDuration: timeout,
+				allCollectors:     len(requestedCollectors) == 0,
+				maxConcurrency:    *maxConcurrency,
 			}
 		},
 	}
 
 	http.HandleFunc(*metricsPath, withConcurrencyLimit(*maxRequests, h.ServeHTTP))
 	http.HandleFunc("/health", healthCheck)
+	http.HandleFunc("/-/ready", readyCheck)
 	http.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
 		// we can't use "version" directly as it is a package, and not an object that
 		// can be serialized.
@@ -399,20 +581,109 @@ func main() {
 	log.Infoln("Starting windows_exporter", version.Info())
 	log.Infoln("Build context", version.BuildContext())
 
-	go func() {
-		log.Infoln("Starting server on", *listenAddress)
-		server := &http.Server{Addr: *listenAddress}
-		if err := web.ListenAndServe(server, *webConfig, log.NewToolkitAdapter()); err != nil {
-			log.Fatalf("cannot start windows_exporter: %s", err)
+	addresses, err := parseListenAddresses(*listenAddresses)
+	if err != nil {
+		log.Fatalf("Invalid telemetry.addr: %v", err)
+	}
+	if len(addresses) == 0 && *listenPipe == "" {
+		log.Fatalf("No listen address or named pipe specified")
+	}
+
+	var servers []*http.Server
+	for _, address := range addresses {
+		server := &http.Server{Addr: address}
+		servers = append(servers, server)
+		go func() {
+			log.Infoln("Starting server on", server.Addr)
+			if err := web.ListenAndServe(server, *webConfig, log.NewToolkitAdapter()); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("cannot start windows_exporter on %s: %s", server.Addr, err)
+			}
+		}()
+	}
+
+	if *listenPipe != "" {
+		sids, err := lookupPipeAccounts(*listenPipeAllow)
+		if err != nil {
+			log.Fatalf("Invalid web.listen-pipe-allow: %v", err)
 		}
-	}()
+		pipeConfig := &winio.PipeConfig{SecurityDescriptor: pipeSecurityDescriptor(sids)}
+		server := &http.Server{}
+		servers = append(servers, server)
+		go func() {
+			log.Infoln("Starting server on named pipe", *listenPipe)
+			listener, err := winio.ListenPipe(*listenPipe, pipeConfig)
+			if err != nil {
+				log.Fatalf("cannot listen on named pipe %s: %s", *listenPipe, err)
+			}
+			if err := web.Serve(listener, server, *webConfig, log.NewToolkitAdapter()); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("cannot start windows_exporter: %s", err)
+			}
+		}()
+	}
 
-	for {
-		if <-stopCh {
-			log.Info("Shutting down windows_exporter")
-			break
+	stoppedByService := false
+	select {
+	case <-stopCh:
+		stoppedByService = true
+	case sig := <-sigCh:
+		log.Infof("Received %s", sig)
+	}
+	log.Info("Shutting down windows_exporter")
+	shutdownServers(servers, *shutdownGracePeriod)
+
+	// Let the service report that it stopped before exiting.
+	close(stoppedCh)
+	if stoppedByService {
+		<-serviceDone
+	}
+}
+
+// parseListenAddresses returns the addresses to listen on, from the values of
+// the repeatable telemetry.addr flag, each of which may be a comma-separated
+// list. Empty values are ignored, so that telemetry.addr="" only serves the
+// named pipe.
+func parseListenAddresses(values []string) ([]string, error) {
+	var addresses []string
+	seen := map[string]bool{}
+	for _, value := range values {
+		for _, address := range strings.Split(value, ",") {
+			address = strings.TrimSpace(address)
+			if address == "" || seen[address] {
+				continue
+			}
+			_, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := net.LookupPort("tcp", port); err != nil {
+				return nil, fmt.Errorf("address %s: %v", address, err)
+			}
+			seen[address] = true
+			addresses = append(addresses, address)
 		}
 	}
+	return addresses, nil
+}
+
+// shutdownServers stops the servers from accepting new connections, and waits
+// for in-flight scrapes to complete for up to gracePeriod, after which the
+// remaining connections are closed, cancelling their scrapes.
+func shutdownServers(servers []*http.Server, gracePeriod time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Warnf("Scrapes still in flight after %s, closing their connections: %v", gracePeriod, err)
+				_ = server.Close()
+			}
+		}(server)
+	}
+	wg.Wait()
 }
 
 func healthCheck(w http.ResponseWriter, r *http.Request) {
@@ -423,6 +694,43 @@ func healthCheck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// readyCheck reports 503 until the first scrape of all enabled collectors
+// has succeeded, so that traffic is only routed to a warmed-up exporter.
+func readyCheck(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	body := `{"status":"ok"}`
+	if atomic.LoadInt32(&ready) == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		body = `{"status":"not ready"}`
+	}
+	_, err := fmt.Fprintln(w, body)
+	if err != nil {
+		log.Debugf("Failed to write to stream: %v", err)
+	}
+}
+
+// filterCollectors returns the enabled collectors requested by the collect[]
+// parameters of a scrape, or all enabled collectors if none is requested.
+func filterCollectors(enabled map[string]collector.Collector, requested []string) (map[string]collector.Collector, error) {
+	if len(requested) == 0 {
+		return enabled, nil
+	}
+	filtered := make(map[string]collector.Collector, len(requested))
+	for _, name := range requested {
+		c, ok := enabled[name]
+		if !ok {
+			for _, registered := range collector.Collectors() {
+				if name == registered {
+					return nil, fmt.Errorf("collector %s is not enabled", name)
+				}
+			}
+			return nil, fmt.Errorf("unavailable collector: %s", name)
+		}
+		filtered[name] = c
+	}
+	return filtered, nil
+}
+
 func keys(m map[string]collector.Collector) []string {
 	ret := make([]string, 0, len(m))
 	for key := range m {
@@ -452,6 +760,9 @@ func withConcurrencyLimit(n int, next http.HandlerFunc) http.HandlerFunc {
 
 type windowsExporterService struct {
 	stopCh chan<- bool
+	// stoppedCh is closed once the exporter shut down.
+	stoppedCh           <-chan struct{}
+	shutdownGracePeriod time.Duration
 }
 
 func (s *windowsExporterService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (ssec bool, errno uint32) {
@@ -473,18 +784,58 @@ loop:
 			}
 		}
 	}
-	changes <- svc.Status{State: svc.StopPending}
+	// Give the exporter time to drain in-flight scrapes before reporting the
+	// service as stopped.
+	changes <- svc.Status{State: svc.StopPending, WaitHint: uint32((s.shutdownGracePeriod + 5*time.Second) / time.Millisecond)}
+	<-s.stoppedCh
 	return
 }
 
+// pipeSecurityDescriptor returns the security descriptor of the named pipe,
+// in SDDL. It grants full control to LocalSystem and Administrators, and read
+// and write access, which sending a request over the pipe requires, to the
+// given SIDs. Other users, including anonymous ones, have no access.
+func pipeSecurityDescriptor(sids []string) string {
+	sddl := "D:P(A;;GA;;;SY)(A;;GA;;;BA)"
+	for _, sid := range sids {
+		sddl += fmt.Sprintf("(A;;GRGW;;;%s)", sid)
+	}
+	return sddl
+}
+
+// lookupPipeAccounts resolves the accounts allowed to access the named pipe to
+// SIDs. Accounts given as SIDs are kept as is.
+func lookupPipeAccounts(accounts []string) ([]string, error) {
+	sids := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		if strings.HasPrefix(strings.ToUpper(account), "S-1-") {
+			sids = append(sids, account)
+			continue
+		}
+		sid, err := winio.LookupSidByName(account)
+		if err != nil {
+			return nil, err
+		}
+		sids = append(sids, sid)
+	}
+	return sids, nil
+}
+
 type metricsHandler struct {
-	timeoutMargin    float64
-	collectorFactory func(timeout time.Duration, requestedCollectors []string) (error, *windowsCollector)
+	timeoutMargin float64
+	// disableCompression serves uncompressed responses, even to clients
+	// accepting gzip.
+	disableCompression bool
+	collectorFactory   func(timeout time.Duration, requestedCollectors []string) (error, *windowsCollector)
 }
 
 func (mh *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	const defaultTimeout = 10.0
 
+	scrapesTotal.Inc()
+	scrapesInFlight.Inc()
+	defer scrapesInFlight.Dec()
+
 	var timeoutSeconds float64
 	if v := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"); v != "" {
 		var err error
@@ -506,13 +857,20 @@ func (mh *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(fmt.Sprintf("Couldn't create filtered metrics handler: %s", err)))
 		return
 	}
+	wc.done = r.Context().Done()
 	reg.MustRegister(wc)
 	reg.MustRegister(
 		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
 		prometheus.NewGoCollector(),
 		version.NewCollector("windows_exporter"),
+		collector.WMIRetriesTotal,
+		collector.WMIResultRows,
+		scrapesInFlight,
+		scrapesTotal,
 	)
 
-	h := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	h := promhttp.HandlerFor(reg, promhttp.HandlerOpts{
+		DisableCompression: mh.disableCompression,
+	})
 	h.ServeHTTP(w, r)
 }
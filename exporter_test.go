@@ -3,9 +3,24 @@
 package main
 
 import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/prometheus-community/windows_exporter/collector"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 )
 
 type expansionTestCase struct {
@@ -26,6 +41,8 @@ func TestExpandEnabled(t *testing.T) {
 		{defaultCollectorsPlaceholder + "," + defaultCollectorsPlaceholder, strings.Split(defaultCollectors, ",")},
 		// Composite case
 		{"foo," + defaultCollectorsPlaceholder + ",bar", append(strings.Split(defaultCollectors, ","), "foo", "bar")},
+		// Whitespace around names
+		{"cs, os ,", []string{"cs", "os"}},
 	}
 
 	for _, testCase := range expansionTests {
@@ -49,3 +66,486 @@ func TestExpandEnabled(t *testing.T) {
 		}
 	}
 }
+
+func TestReadyCheck(t *testing.T) {
+	defer atomic.StoreInt32(&ready, 0)
+
+	for _, c := range []struct {
+		ready int32
+		code  int
+	}{
+		{0, http.StatusServiceUnavailable},
+		{1, http.StatusOK},
+	} {
+		atomic.StoreInt32(&ready, c.ready)
+		rec := httptest.NewRecorder()
+		readyCheck(rec, httptest.NewRequest("GET", "/-/ready", nil))
+		if rec.Code != c.code {
+			t.Errorf("ready=%d: expected status %d, got %d", c.ready, c.code, rec.Code)
+		}
+	}
+}
+
+// sleepingCollector tracks the number of collectors running at once.
+type sleepingCollector struct {
+	duration time.Duration
+	running  *int32
+	peak     *int32
+}
+
+func (c sleepingCollector) Collect(ctx *collector.ScrapeContext, ch chan<- prometheus.Metric) error {
+	n := atomic.AddInt32(c.running, 1)
+	for {
+		peak := atomic.LoadInt32(c.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(c.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(c.duration)
+	atomic.AddInt32(c.running, -1)
+	return nil
+}
+
+// failingCollector always fails.
+type failingCollector struct{}
+
+func (c failingCollector) Collect(ctx *collector.ScrapeContext, ch chan<- prometheus.Metric) error {
+	return errors.New("failed")
+}
+
+// seriesCollector sends the given number of series, shaped like the state
+// series of the service collector.
+type seriesCollector struct {
+	series int
+	desc   *prometheus.Desc
+}
+
+func newSeriesCollector(series int) seriesCollector {
+	return seriesCollector{
+		series: series,
+		desc: prometheus.NewDesc(
+			"windows_service_state",
+			"The state of the service (State)",
+			[]string{"name", "state"},
+			nil,
+		),
+	}
+}
+
+func (c seriesCollector) Collect(ctx *collector.ScrapeContext, ch chan<- prometheus.Metric) error {
+	for i := 0; i < c.series; i++ {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(i%2), fmt.Sprintf("service%d", i/2), fmt.Sprintf("state%d", i%2))
+	}
+	return nil
+}
+
+// collectOutcomes runs a scrape, and returns the value of the success and
+// timeout metrics of each collector.
+func collectOutcomes(coll *windowsCollector) (success, timeout map[string]float64) {
+	initExporterMetrics()
+	ch := make(chan prometheus.Metric)
+	go func() {
+		coll.Collect(ch)
+		close(ch)
+	}()
+
+	success = make(map[string]float64)
+	timeout = make(map[string]float64)
+	for m := range ch {
+		var pb dto.Metric
+		if err := m.Write(&pb); err != nil || len(pb.Label) == 0 {
+			continue
+		}
+		switch m.Desc() {
+		case scrapeSuccessDesc:
+			success[pb.Label[0].GetValue()] = pb.Gauge.GetValue()
+		case scrapeTimeoutDesc:
+			timeout[pb.Label[0].GetValue()] = pb.Gauge.GetValue()
+		}
+	}
+	return success, timeout
+}
+
+func TestCollectMaxConcurrency(t *testing.T) {
+	var running, peak int32
+	collectors := make(map[string]collector.Collector)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		collectors[name] = sleepingCollector{duration: 20 * time.Millisecond, running: &running, peak: &peak}
+	}
+
+	success, _ := collectOutcomes(&windowsCollector{
+		collectors:        collectors,
+		maxScrapeDuration: 5 * time.Second,
+		maxConcurrency:    2,
+	})
+	if peak > 2 {
+		t.Errorf("expected at most 2 collectors running at once, got %d", peak)
+	}
+	for name := range collectors {
+		if success[name] != 1 {
+			t.Errorf("expected collector %s to succeed", name)
+		}
+	}
+}
+
+func TestCollectMaxConcurrencyTimeout(t *testing.T) {
+	var running, peak int32
+	collectors := map[string]collector.Collector{
+		"slow":  sleepingCollector{duration: time.Second, running: &running, peak: &peak},
+		"slow2": sleepingCollector{duration: time.Second, running: &running, peak: &peak},
+	}
+
+	start := time.Now()
+	success, timeout := collectOutcomes(&windowsCollector{
+		collectors:        collectors,
+		maxScrapeDuration: 100 * time.Millisecond,
+		maxConcurrency:    1,
+	})
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("expected the scrape to end at its timeout, took %s", elapsed)
+	}
+	for name := range collectors {
+		if success[name] != 0 || timeout[name] != 1 {
+			t.Errorf("expected collector %s to time out", name)
+		}
+	}
+}
+
+func TestFilterCollectors(t *testing.T) {
+	enabled := map[string]collector.Collector{
+		"cpu": sleepingCollector{},
+		"os":  sleepingCollector{},
+	}
+
+	if filtered, err := filterCollectors(enabled, nil); err != nil || len(filtered) != 2 {
+		t.Errorf("expected all enabled collectors, got %v, %v", keys(filtered), err)
+	}
+	if filtered, err := filterCollectors(enabled, []string{"os", "os"}); err != nil || len(filtered) != 1 || filtered["os"] == nil {
+		t.Errorf("expected the os collector, got %v, %v", keys(filtered), err)
+	}
+	if _, err := filterCollectors(enabled, []string{"cpu", "service"}); err == nil || !strings.Contains(err.Error(), "not enabled") {
+		t.Errorf("expected an error for a collector which is not enabled, got %v", err)
+	}
+	if _, err := filterCollectors(enabled, []string{"foo"}); err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Errorf("expected an error for an unknown collector, got %v", err)
+	}
+}
+
+func TestValidateCollectorNames(t *testing.T) {
+	available := []string{"cpu", "cs", "os"}
+
+	if err := validateCollectorNames(expandEnabledCollectors("cpu,os,cpu"), available); err != nil {
+		t.Errorf("expected duplicate names to be accepted, got %v", err)
+	}
+	if err := validateCollectorNames(nil, available); err != nil {
+		t.Errorf("expected no error without collectors, got %v", err)
+	}
+
+	err := validateCollectorNames([]string{"os", "foo", "bar"}, available)
+	if err == nil {
+		t.Fatal("expected an error for unknown collectors")
+	}
+	for _, want := range []string{"bar, foo", "cpu, cs, os"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %q", want, err)
+		}
+	}
+}
+
+func TestDefaultCollectorsAreRegistered(t *testing.T) {
+	names := expandEnabledCollectors(defaultCollectorsPlaceholder)
+	if err := validateCollectorNames(names, collector.Collectors()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBuildCollectors(t *testing.T) {
+	build := func(name string) (collector.Collector, error) {
+		if name == "cpu" {
+			return sleepingCollector{}, nil
+		}
+		return nil, fmt.Errorf("invalid %s flag", name)
+	}
+
+	if collectors, err := buildCollectors([]string{"cpu"}, build); err != nil || len(collectors) != 1 {
+		t.Errorf("expected the cpu collector, got %v, %v", keys(collectors), err)
+	}
+
+	_, err := buildCollectors([]string{"service", "cpu", "process"}, build)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"2 collector(s)", "service: invalid service flag", "process: invalid process flag"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error %q to contain %q", err, want)
+		}
+	}
+}
+
+func TestCollectReady(t *testing.T) {
+	defer atomic.StoreInt32(&ready, 0)
+	var running, peak int32
+	cases := []struct {
+		name       string
+		collectors map[string]collector.Collector
+		ready      int32
+	}{
+		{
+			name: "all succeeded",
+			collectors: map[string]collector.Collector{
+				"a": sleepingCollector{running: &running, peak: &peak},
+			},
+			ready: 1,
+		},
+		{
+			name: "one failed",
+			collectors: map[string]collector.Collector{
+				"a":      sleepingCollector{running: &running, peak: &peak},
+				"broken": failingCollector{},
+			},
+			ready: 0,
+		},
+	}
+
+	for _, c := range cases {
+		atomic.StoreInt32(&ready, 0)
+		collectOutcomes(&windowsCollector{
+			collectors:        c.collectors,
+			maxScrapeDuration: 5 * time.Second,
+			allCollectors:     true,
+		})
+		if got := atomic.LoadInt32(&ready); got != c.ready {
+			t.Errorf("%s: expected ready %d, got %d", c.name, c.ready, got)
+		}
+	}
+}
+
+func TestCollectCancelled(t *testing.T) {
+	var running, peak int32
+	collectors := map[string]collector.Collector{
+		"slow": sleepingCollector{duration: time.Second, running: &running, peak: &peak},
+	}
+	done := make(chan struct{})
+	close(done)
+
+	start := time.Now()
+	success, timeout := collectOutcomes(&windowsCollector{
+		collectors:        collectors,
+		maxScrapeDuration: 5 * time.Second,
+		done:              done,
+	})
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("expected the scrape to end when cancelled, took %s", elapsed)
+	}
+	if success["slow"] != 0 || timeout["slow"] != 1 {
+		t.Error("expected collector slow to be reported as timed out")
+	}
+}
+
+func TestParseListenAddresses(t *testing.T) {
+	cases := []struct {
+		values []string
+		want   []string
+	}{
+		{[]string{":9182"}, []string{":9182"}},
+		{[]string{"127.0.0.1:9182", "[::1]:9182"}, []string{"127.0.0.1:9182", "[::1]:9182"}},
+		{[]string{"127.0.0.1:9182, 10.0.0.5:9182", "127.0.0.1:9182"}, []string{"127.0.0.1:9182", "10.0.0.5:9182"}},
+		{[]string{""}, nil},
+	}
+	for _, c := range cases {
+		got, err := parseListenAddresses(c.values)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", c.values, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%v: expected %v, got %v", c.values, c.want, got)
+		}
+	}
+
+	for _, invalid := range []string{"9182", "localhost", "127.0.0.1:http-alt-port"} {
+		if _, err := parseListenAddresses([]string{invalid}); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+type describedCollector struct {
+	Info *prometheus.Desc
+}
+
+func (c describedCollector) Collect(ctx *collector.ScrapeContext, ch chan<- prometheus.Metric) error {
+	return nil
+}
+
+func TestPrintDescriptors(t *testing.T) {
+	collectors := map[string]collector.Collector{
+		"b": &describedCollector{Info: prometheus.NewDesc("windows_b_info", "B info.", []string{"name"}, nil)},
+		"a": &describedCollector{Info: prometheus.NewDesc("windows_a_info", "A info.", nil, nil)},
+	}
+
+	var out strings.Builder
+	printDescriptors(&out, collectors)
+
+	expected := `a
+  Desc{fqName: "windows_a_info", help: "A info.", constLabels: {}, variableLabels: []}
+b
+  Desc{fqName: "windows_b_info", help: "B info.", constLabels: {}, variableLabels: [name]}
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestMetricsHandlerCompression(t *testing.T) {
+	initExporterMetrics()
+	var running, peak int32
+	for _, c := range []struct {
+		disableCompression bool
+		acceptEncoding     string
+		gzipped            bool
+	}{
+		{false, "gzip", true},
+		{false, "", false},
+		{true, "gzip", false},
+	} {
+		mh := &metricsHandler{
+			disableCompression: c.disableCompression,
+			collectorFactory: func(timeout time.Duration, requestedCollectors []string) (error, *windowsCollector) {
+				return nil, &windowsCollector{
+					collectors:        map[string]collector.Collector{"a": sleepingCollector{running: &running, peak: &peak}},
+					maxScrapeDuration: timeout,
+				}
+			},
+		}
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if c.acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", c.acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		mh.ServeHTTP(rec, req)
+
+		if gzipped := rec.Header().Get("Content-Encoding") == "gzip"; gzipped != c.gzipped {
+			t.Fatalf("%+v: expected gzipped %t, got Content-Encoding %q", c, c.gzipped, rec.Header().Get("Content-Encoding"))
+		}
+		body := io.Reader(rec.Body)
+		if c.gzipped {
+			gz, err := gzip.NewReader(rec.Body)
+			if err != nil {
+				t.Fatalf("%+v: invalid gzip response: %v", c, err)
+			}
+			body = gz
+		}
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			t.Fatalf("%+v: reading response: %v", c, err)
+		}
+		if !strings.Contains(string(b), `windows_exporter_collector_success{collector="a"} 1`) {
+			t.Errorf("%+v: collector success missing from response:\n%s", c, b)
+		}
+	}
+}
+
+// prometheusAccept is the Accept header sent by Prometheus, preferring the
+// protobuf exposition format.
+const prometheusAccept = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,text/plain;version=0.0.4;q=0.3,*/*;q=0.1`
+
+func seriesMetricsHandler(series int) *metricsHandler {
+	c := newSeriesCollector(series)
+	return &metricsHandler{
+		disableCompression: true,
+		collectorFactory: func(timeout time.Duration, requestedCollectors []string) (error, *windowsCollector) {
+			return nil, &windowsCollector{
+				collectors:        map[string]collector.Collector{"service": c},
+				maxScrapeDuration: timeout,
+			}
+		},
+	}
+}
+
+func TestMetricsHandlerNegotiation(t *testing.T) {
+	initExporterMetrics()
+	mh := seriesMetricsHandler(10)
+	for _, c := range []struct {
+		accept string
+		format expfmt.Format
+	}{
+		{"", expfmt.FmtText},
+		{"text/plain", expfmt.FmtText},
+		{prometheusAccept, expfmt.FmtProtoDelim},
+	} {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if c.accept != "" {
+			req.Header.Set("Accept", c.accept)
+		}
+		rec := httptest.NewRecorder()
+		mh.ServeHTTP(rec, req)
+
+		format := expfmt.Format(rec.Header().Get("Content-Type"))
+		if format != c.format {
+			t.Fatalf("Accept %q: expected format %q, got %q", c.accept, c.format, format)
+		}
+
+		families := make(map[string]*dto.MetricFamily)
+		dec := expfmt.NewDecoder(rec.Body, format)
+		for {
+			var mf dto.MetricFamily
+			if err := dec.Decode(&mf); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Accept %q: decoding response: %v", c.accept, err)
+			}
+			families[mf.GetName()] = &mf
+		}
+		if n := len(families["windows_service_state"].GetMetric()); n != 10 {
+			t.Errorf("Accept %q: expected 10 windows_service_state series, got %d", c.accept, n)
+		}
+	}
+}
+
+// BenchmarkMetricsHandler compares serving a scrape with tens of thousands of
+// series in the text and protobuf exposition formats.
+func BenchmarkMetricsHandler(b *testing.B) {
+	initExporterMetrics()
+	mh := seriesMetricsHandler(50000)
+	for _, bm := range []struct {
+		name   string
+		accept string
+	}{
+		{"text", "text/plain"},
+		{"protobuf", prometheusAccept},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest("GET", "/metrics", nil)
+				req.Header.Set("Accept", bm.accept)
+				rec := httptest.NewRecorder()
+				mh.ServeHTTP(rec, req)
+				size = rec.Body.Len()
+			}
+			b.ReportMetric(float64(size), "bytes/scrape")
+		})
+	}
+}
+
+func TestPipeSecurityDescriptor(t *testing.T) {
+	if got, expected := pipeSecurityDescriptor(nil), "D:P(A;;GA;;;SY)(A;;GA;;;BA)"; got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	got := pipeSecurityDescriptor([]string{"S-1-5-21-1004336348-1177238915-682003330-1001"})
+	expected := "D:P(A;;GA;;;SY)(A;;GA;;;BA)(A;;GRGW;;;S-1-5-21-1004336348-1177238915-682003330-1001)"
+	if got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestLookupPipeAccounts(t *testing.T) {
+	sids, err := lookupPipeAccounts([]string{"S-1-5-18", "s-1-5-32-544"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"S-1-5-18", "s-1-5-32-544"}; !reflect.DeepEqual(sids, expected) {
+		t.Errorf("expected %v, got %v", expected, sids)
+	}
+}
@@ -0,0 +1,85 @@
+package dhcpsapi
+
+import (
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// dhcpMibInfo is a wrapper for DHCP_MIB_INFO
+// https://docs.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-dhcp_mib_info
+type dhcpMibInfo struct {
+	Discovers       uint32
+	Offers          uint32
+	Requests        uint32
+	Acks            uint32
+	Naks            uint32
+	Declines        uint32
+	Releases        uint32
+	ServerStartTime windows.Filetime
+	Scopes          uint32
+	ScopeInfo       *scopeMibInfo
+}
+
+// scopeMibInfo is a wrapper for SCOPE_MIB_INFO
+// https://docs.microsoft.com/en-us/windows/win32/api/dhcpsapi/ns-dhcpsapi-scope_mib_info
+type scopeMibInfo struct {
+	Subnet            uint32
+	NumAddressesInuse uint32
+	NumAddressesFree  uint32
+	NumPendingOffers  uint32
+}
+
+// ScopeStatistics is an idiomatic wrapper for SCOPE_MIB_INFO
+type ScopeStatistics struct {
+	Subnet         net.IP
+	AddressesInUse uint32
+	AddressesFree  uint32
+	PendingOffers  uint32
+}
+
+var (
+	dhcpsapi              = windows.NewLazySystemDLL("dhcpsapi.dll")
+	procDhcpGetMibInfo    = dhcpsapi.NewProc("DhcpGetMibInfo")
+	procDhcpRpcFreeMemory = dhcpsapi.NewProc("DhcpRpcFreeMemory")
+)
+
+// GetScopeStatistics returns the address usage of the IPv4 scopes of the
+// local DHCP server.
+// https://docs.microsoft.com/en-us/windows/win32/api/dhcpsapi/nf-dhcpsapi-dhcpgetmibinfo
+func GetScopeStatistics() ([]ScopeStatistics, error) {
+	if err := procDhcpGetMibInfo.Find(); err != nil {
+		return nil, err
+	}
+
+	var info *dhcpMibInfo
+	r1, _, _ := procDhcpGetMibInfo.Call(0, uintptr(unsafe.Pointer(&info)))
+	if r1 != 0 {
+		return nil, windows.Errno(r1)
+	}
+	defer procDhcpRpcFreeMemory.Call(uintptr(unsafe.Pointer(info)))
+	if info.ScopeInfo != nil {
+		defer procDhcpRpcFreeMemory.Call(uintptr(unsafe.Pointer(info.ScopeInfo)))
+	}
+
+	if info.Scopes == 0 {
+		return nil, nil
+	}
+	scopes := make([]ScopeStatistics, 0, info.Scopes)
+	for _, scope := range (*[1 << 20]scopeMibInfo)(unsafe.Pointer(info.ScopeInfo))[:info.Scopes:info.Scopes] {
+		scopes = append(scopes, ScopeStatistics{
+			Subnet:         IPFromAddress(scope.Subnet),
+			AddressesInUse: scope.NumAddressesInuse,
+			AddressesFree:  scope.NumAddressesFree,
+			PendingOffers:  scope.NumPendingOffers,
+		})
+	}
+	return scopes, nil
+}
+
+// IPFromAddress converts a DHCP_IP_ADDRESS, an IPv4 address in host byte
+// order, to a net.IP.
+func IPFromAddress(addr uint32) net.IP {
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr))
+}
@@ -0,0 +1,196 @@
+package iphlpapi
+
+import (
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Interface types.
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/ns-netioapi-mib_if_row2
+const (
+	IF_TYPE_SOFTWARE_LOOPBACK = 24
+	IF_TYPE_TUNNEL            = 131
+)
+
+// Bits of MibIfRow2.InterfaceAndOperStatusFlags.
+const (
+	InterfaceHardware = 1 << 0
+	InterfaceFilter   = 1 << 1
+)
+
+const (
+	ifMaxStringSize        = 256
+	ifMaxPhysAddressLength = 32
+)
+
+// MibIfRow2 is a wrapper for MIB_IF_ROW2
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/ns-netioapi-mib_if_row2
+type MibIfRow2 struct {
+	InterfaceLuid               uint64
+	InterfaceIndex              uint32
+	InterfaceGuid               windows.GUID
+	Alias                       [ifMaxStringSize + 1]uint16
+	Description                 [ifMaxStringSize + 1]uint16
+	PhysicalAddressLength       uint32
+	PhysicalAddress             [ifMaxPhysAddressLength]byte
+	PermanentPhysicalAddress    [ifMaxPhysAddressLength]byte
+	Mtu                         uint32
+	Type                        uint32
+	TunnelType                  uint32
+	MediaType                   uint32
+	PhysicalMediumType          uint32
+	AccessType                  uint32
+	DirectionType               uint32
+	InterfaceAndOperStatusFlags uint8
+	OperStatus                  uint32
+	AdminStatus                 uint32
+	MediaConnectState           uint32
+	NetworkGuid                 windows.GUID
+	ConnectionType              uint32
+	_                           [4]byte // Explicit alignment of the 64-bit fields, for 386
+	TransmitLinkSpeed           uint64
+	ReceiveLinkSpeed            uint64
+	InOctets                    uint64
+	InUcastPkts                 uint64
+	InNUcastPkts                uint64
+	InDiscards                  uint64
+	InErrors                    uint64
+	InUnknownProtos             uint64
+	InUcastOctets               uint64
+	InMulticastOctets           uint64
+	InBroadcastOctets           uint64
+	OutOctets                   uint64
+	OutUcastPkts                uint64
+	OutNUcastPkts               uint64
+	OutDiscards                 uint64
+	OutErrors                   uint64
+	OutUcastOctets              uint64
+	OutMulticastOctets          uint64
+	OutBroadcastOctets          uint64
+	OutQLen                     uint64
+}
+
+// mibIfTable2 is a wrapper for MIB_IF_TABLE2
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/ns-netioapi-mib_if_table2
+type mibIfTable2 struct {
+	NumEntries uint32
+	_          [4]byte // Explicit alignment of the 64-bit fields, for 386
+	Table      [1]MibIfRow2
+}
+
+var (
+	iphlpapi         = windows.NewLazySystemDLL("iphlpapi.dll")
+	procGetIfTable2  = iphlpapi.NewProc("GetIfTable2")
+	procFreeMibTable = iphlpapi.NewProc("FreeMibTable")
+)
+
+// GetIfTable2 returns the rows of the interface table, with 64-bit statistics.
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/nf-netioapi-getiftable2
+func GetIfTable2() ([]MibIfRow2, error) {
+	var table *mibIfTable2
+	r1, _, _ := procGetIfTable2.Call(uintptr(unsafe.Pointer(&table)))
+	if r1 != 0 {
+		return nil, windows.Errno(r1)
+	}
+	defer procFreeMibTable.Call(uintptr(unsafe.Pointer(table)))
+
+	rows := make([]MibIfRow2, table.NumEntries)
+	for i := range rows {
+		rows[i] = *(*MibIfRow2)(unsafe.Pointer(uintptr(unsafe.Pointer(&table.Table[0])) + uintptr(i)*unsafe.Sizeof(table.Table[0])))
+	}
+	return rows, nil
+}
+
+// DescriptionString returns the description of the interface, as shown in
+// the Network Interface performance counters.
+func (r *MibIfRow2) DescriptionString() string {
+	return windows.UTF16ToString(r.Description[:])
+}
+
+// AliasString returns the alias of the interface, e.g. "Ethernet", as shown
+// by Get-NetAdapter.
+func (r *MibIfRow2) AliasString() string {
+	return windows.UTF16ToString(r.Alias[:])
+}
+
+// SockaddrInet is a wrapper for SOCKADDR_INET, the union of an IPv4 and an
+// IPv6 socket address.
+// https://docs.microsoft.com/en-us/windows/win32/api/ws2ipdef/ns-ws2ipdef-sockaddr_inet
+type SockaddrInet [28]byte
+
+// Family returns the address family of the socket address, AF_INET or AF_INET6.
+func (s *SockaddrInet) Family() uint16 {
+	return uint16(s[0]) | uint16(s[1])<<8
+}
+
+// IP returns the IP address of the socket address, or nil if its family is
+// neither AF_INET nor AF_INET6.
+func (s *SockaddrInet) IP() net.IP {
+	switch s.Family() {
+	case windows.AF_INET:
+		return net.IPv4(s[4], s[5], s[6], s[7])
+	case windows.AF_INET6:
+		ip := make(net.IP, net.IPv6len)
+		copy(ip, s[8:24])
+		return ip
+	}
+	return nil
+}
+
+// IPAddressPrefix is a wrapper for IP_ADDRESS_PREFIX
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/ns-netioapi-ip_address_prefix
+type IPAddressPrefix struct {
+	Prefix       SockaddrInet
+	PrefixLength uint8
+	_            [3]byte
+}
+
+// MibIPForwardRow2 is a wrapper for MIB_IPFORWARD_ROW2
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/ns-netioapi-mib_ipforward_row2
+type MibIPForwardRow2 struct {
+	InterfaceLuid        uint64
+	InterfaceIndex       uint32
+	DestinationPrefix    IPAddressPrefix
+	NextHop              SockaddrInet
+	SitePrefixLength     uint8
+	ValidLifetime        uint32
+	PreferredLifetime    uint32
+	Metric               uint32
+	Protocol             uint32
+	Loopback             uint8
+	AutoconfigureAddress uint8
+	Publish              uint8
+	Immortal             uint8
+	Age                  uint32
+	Origin               uint32
+}
+
+// mibIPForwardTable2 is a wrapper for MIB_IPFORWARD_TABLE2
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/ns-netioapi-mib_ipforward_table2
+type mibIPForwardTable2 struct {
+	NumEntries uint32
+	_          [4]byte // Explicit alignment of the 64-bit fields, for 386
+	Table      [1]MibIPForwardRow2
+}
+
+var procGetIpForwardTable2 = iphlpapi.NewProc("GetIpForwardTable2")
+
+// GetIPForwardTable2 returns the rows of the route table of the given address
+// family: AF_INET, AF_INET6, or AF_UNSPEC for both.
+// https://docs.microsoft.com/en-us/windows/win32/api/netioapi/nf-netioapi-getipforwardtable2
+func GetIPForwardTable2(family uint16) ([]MibIPForwardRow2, error) {
+	var table *mibIPForwardTable2
+	r1, _, _ := procGetIpForwardTable2.Call(uintptr(family), uintptr(unsafe.Pointer(&table)))
+	if r1 != 0 {
+		return nil, windows.Errno(r1)
+	}
+	defer procFreeMibTable.Call(uintptr(unsafe.Pointer(table)))
+
+	rows := make([]MibIPForwardRow2, table.NumEntries)
+	for i := range rows {
+		rows[i] = *(*MibIPForwardRow2)(unsafe.Pointer(uintptr(unsafe.Pointer(&table.Table[0])) + uintptr(i)*unsafe.Sizeof(table.Table[0])))
+	}
+	return rows, nil
+}
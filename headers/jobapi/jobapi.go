@@ -0,0 +1,49 @@
+package jobapi
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Access rights for job objects.
+// https://docs.microsoft.com/en-us/windows/win32/procthread/job-object-security-and-access-rights
+const (
+	JOB_OBJECT_QUERY = 0x0004
+)
+
+var (
+	kernel32           = windows.NewLazySystemDLL("kernel32.dll")
+	procIsProcessInJob = kernel32.NewProc("IsProcessInJob")
+	procOpenJobObjectW = kernel32.NewProc("OpenJobObjectW")
+)
+
+// IsProcessInJob determines whether the process is running in the specified job.
+// If job is 0, it determines whether the process is running in any job.
+// https://docs.microsoft.com/en-us/windows/win32/api/jobapi/nf-jobapi-isprocessinjob
+func IsProcessInJob(process windows.Handle, job windows.Handle) (bool, error) {
+	var result int32
+	r1, _, err := procIsProcessInJob.Call(uintptr(process), uintptr(job), uintptr(unsafe.Pointer(&result)))
+	if r1 == 0 {
+		return false, err
+	}
+	return result != 0, nil
+}
+
+// OpenJobObject opens an existing named job object.
+// https://docs.microsoft.com/en-us/windows/win32/api/jobapi2/nf-jobapi2-openjobobjectw
+func OpenJobObject(desiredAccess uint32, inheritHandle bool, name string) (windows.Handle, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, err
+	}
+	var inherit uintptr
+	if inheritHandle {
+		inherit = 1
+	}
+	r1, _, err := procOpenJobObjectW.Call(uintptr(desiredAccess), inherit, uintptr(unsafe.Pointer(namePtr)))
+	if r1 == 0 {
+		return 0, err
+	}
+	return windows.Handle(r1), nil
+}
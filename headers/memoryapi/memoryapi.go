@@ -0,0 +1,50 @@
+package memoryapi
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Flags returned by GetSystemFileCacheSize
+const (
+	FILE_CACHE_MAX_HARD_ENABLE = 0x1
+	FILE_CACHE_MIN_HARD_ENABLE = 0x4
+)
+
+var (
+	kernel32                   = windows.NewLazySystemDLL("kernel32.dll")
+	procGetSystemFileCacheSize = kernel32.NewProc("GetSystemFileCacheSize")
+)
+
+// FileCacheSize holds the limits of the working set of the system file cache.
+type FileCacheSize struct {
+	Minimum uint64
+	Maximum uint64
+	// MinimumHard and MaximumHard are set when the limits are enforced,
+	// rather than only applied under memory pressure.
+	MinimumHard bool
+	MaximumHard bool
+}
+
+// GetSystemFileCacheSize retrieves the limits of the working set of the
+// system file cache.
+// https://docs.microsoft.com/en-us/windows/win32/api/memoryapi/nf-memoryapi-getsystemfilecachesize
+func GetSystemFileCacheSize() (FileCacheSize, error) {
+	var min, max uintptr
+	var flags uint32
+	r1, _, err := procGetSystemFileCacheSize.Call(
+		uintptr(unsafe.Pointer(&min)),
+		uintptr(unsafe.Pointer(&max)),
+		uintptr(unsafe.Pointer(&flags)),
+	)
+	if r1 == 0 {
+		return FileCacheSize{}, err
+	}
+	return FileCacheSize{
+		Minimum:     uint64(min),
+		Maximum:     uint64(max),
+		MinimumHard: flags&FILE_CACHE_MIN_HARD_ENABLE != 0,
+		MaximumHard: flags&FILE_CACHE_MAX_HARD_ENABLE != 0,
+	}, nil
+}
@@ -0,0 +1,151 @@
+package pdh
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// PDH status codes and formats.
+// https://docs.microsoft.com/en-us/windows/win32/perfctrs/pdh-error-codes
+const (
+	PDH_CSTATUS_VALID_DATA = 0x00000000
+	PDH_CSTATUS_NEW_DATA   = 0x00000001
+	PDH_MORE_DATA          = 0x800007D2
+
+	PDH_FMT_DOUBLE   = 0x00000200
+	PDH_FMT_NOCAP100 = 0x00008000
+)
+
+// PdhError is a PDH status code returned by a failed call.
+type PdhError uint32
+
+func (e PdhError) Error() string {
+	return fmt.Sprintf("PDH error 0x%08X", uint32(e))
+}
+
+// PdhFmtCounterValueItemDouble is a wrapper for PDH_FMT_COUNTERVALUE_ITEM_W,
+// with a PDH_FMT_DOUBLE value
+// https://docs.microsoft.com/en-us/windows/win32/api/pdh/ns-pdh-pdh_fmt_countervalue_item_w
+type PdhFmtCounterValueItemDouble struct {
+	SzName *uint16
+	// The value is 8-byte aligned, also on 386
+	_           [8 - unsafe.Sizeof(uintptr(0))]byte
+	CStatus     uint32
+	_           uint32
+	DoubleValue float64
+}
+
+var (
+	pdh                              = windows.NewLazySystemDLL("pdh.dll")
+	procPdhOpenQueryW                = pdh.NewProc("PdhOpenQueryW")
+	procPdhAddEnglishCounterW        = pdh.NewProc("PdhAddEnglishCounterW")
+	procPdhCollectQueryData          = pdh.NewProc("PdhCollectQueryData")
+	procPdhCollectQueryDataWithTime  = pdh.NewProc("PdhCollectQueryDataWithTime")
+	procPdhGetFormattedCounterArrayW = pdh.NewProc("PdhGetFormattedCounterArrayW")
+	procPdhCloseQuery                = pdh.NewProc("PdhCloseQuery")
+
+	kernel32                    = windows.NewLazySystemDLL("kernel32.dll")
+	procLocalFileTimeToFileTime = kernel32.NewProc("LocalFileTimeToFileTime")
+)
+
+// PdhOpenQuery creates a new query on the local machine.
+// https://docs.microsoft.com/en-us/windows/win32/api/pdh/nf-pdh-pdhopenqueryw
+func PdhOpenQuery() (windows.Handle, error) {
+	var query windows.Handle
+	r1, _, _ := procPdhOpenQueryW.Call(0, 0, uintptr(unsafe.Pointer(&query)))
+	if r1 != 0 {
+		return 0, PdhError(r1)
+	}
+	return query, nil
+}
+
+// PdhAddEnglishCounter adds a counter, with a path using the English object
+// and counter names regardless of the locale, to the query.
+// https://docs.microsoft.com/en-us/windows/win32/api/pdh/nf-pdh-pdhaddenglishcounterw
+func PdhAddEnglishCounter(query windows.Handle, path string) (windows.Handle, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var counter windows.Handle
+	r1, _, _ := procPdhAddEnglishCounterW.Call(uintptr(query), uintptr(unsafe.Pointer(pathPtr)), 0, uintptr(unsafe.Pointer(&counter)))
+	if r1 != 0 {
+		return 0, PdhError(r1)
+	}
+	return counter, nil
+}
+
+// PdhCollectQueryData collects the current values of all counters of the query.
+// https://docs.microsoft.com/en-us/windows/win32/api/pdh/nf-pdh-pdhcollectquerydata
+func PdhCollectQueryData(query windows.Handle) error {
+	r1, _, _ := procPdhCollectQueryData.Call(uintptr(query))
+	if r1 != 0 {
+		return PdhError(r1)
+	}
+	return nil
+}
+
+// PdhCollectQueryDataWithTime collects the current values of all counters of
+// the query, and returns the time they were collected at.
+// https://docs.microsoft.com/en-us/windows/win32/api/pdh/nf-pdh-pdhcollectquerydatawithtime
+func PdhCollectQueryDataWithTime(query windows.Handle) (time.Time, error) {
+	// The timestamp is a FILETIME in local time
+	var local, utc windows.Filetime
+	r1, _, _ := procPdhCollectQueryDataWithTime.Call(uintptr(query), uintptr(unsafe.Pointer(&local)))
+	if r1 != 0 {
+		return time.Time{}, PdhError(r1)
+	}
+	r1, _, err := procLocalFileTimeToFileTime.Call(uintptr(unsafe.Pointer(&local)), uintptr(unsafe.Pointer(&utc)))
+	if r1 == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(0, utc.Nanoseconds()), nil
+}
+
+// PdhFormattedCounterValue is the value of a counter instance.
+type PdhFormattedCounterValue struct {
+	Instance string
+	CStatus  uint32
+	Value    float64
+}
+
+// PdhGetFormattedCounterArrayDouble returns the values of all instances of a
+// counter, as doubles.
+// https://docs.microsoft.com/en-us/windows/win32/api/pdh/nf-pdh-pdhgetformattedcounterarrayw
+func PdhGetFormattedCounterArrayDouble(counter windows.Handle) ([]PdhFormattedCounterValue, error) {
+	var size, count uint32
+	r1, _, _ := procPdhGetFormattedCounterArrayW.Call(uintptr(counter), PDH_FMT_DOUBLE|PDH_FMT_NOCAP100, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), 0)
+	if r1 != PDH_MORE_DATA {
+		return nil, PdhError(r1)
+	}
+
+	buf := make([]byte, size)
+	r1, _, _ = procPdhGetFormattedCounterArrayW.Call(uintptr(counter), PDH_FMT_DOUBLE|PDH_FMT_NOCAP100, uintptr(unsafe.Pointer(&size)), uintptr(unsafe.Pointer(&count)), uintptr(unsafe.Pointer(&buf[0])))
+	if r1 != 0 {
+		return nil, PdhError(r1)
+	}
+
+	values := make([]PdhFormattedCounterValue, 0, count)
+	for i := uint32(0); i < count; i++ {
+		item := (*PdhFmtCounterValueItemDouble)(unsafe.Pointer(&buf[uintptr(i)*unsafe.Sizeof(PdhFmtCounterValueItemDouble{})]))
+		values = append(values, PdhFormattedCounterValue{
+			Instance: windows.UTF16PtrToString(item.SzName),
+			CStatus:  item.CStatus,
+			Value:    item.DoubleValue,
+		})
+	}
+	return values, nil
+}
+
+// PdhCloseQuery closes all counters of the query and the query itself.
+// https://docs.microsoft.com/en-us/windows/win32/api/pdh/nf-pdh-pdhclosequery
+func PdhCloseQuery(query windows.Handle) error {
+	r1, _, _ := procPdhCloseQuery.Call(uintptr(query))
+	if r1 != 0 {
+		return PdhError(r1)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package sysinfoapi
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// relationNumaNode is the RelationNumaNode LOGICAL_PROCESSOR_RELATIONSHIP
+const relationNumaNode = 1
+
+var (
+	procGetLogicalProcessorInformationEx = kernel32.NewProc("GetLogicalProcessorInformationEx")
+	procGetNumaAvailableMemoryNodeEx     = kernel32.NewProc("GetNumaAvailableMemoryNodeEx")
+)
+
+// NumaNode is a NUMA node and the number of logical processors it contains.
+type NumaNode struct {
+	Number         uint32
+	ProcessorCount int
+}
+
+// GetNumaNodes lists the NUMA nodes of the system. Systems without NUMA have
+// a single node 0.
+// https://docs.microsoft.com/en-us/windows/win32/api/sysinfoapi/nf-sysinfoapi-getlogicalprocessorinformationex
+func GetNumaNodes() ([]NumaNode, error) {
+	var size uint32
+	r1, _, err := procGetLogicalProcessorInformationEx.Call(relationNumaNode, 0, uintptr(unsafe.Pointer(&size)))
+	if r1 == 0 && err != windows.ERROR_INSUFFICIENT_BUFFER {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	r1, _, err = procGetLogicalProcessorInformationEx.Call(relationNumaNode, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if r1 == 0 {
+		return nil, err
+	}
+	return parseNumaNodes(buf[:size], int(unsafe.Sizeof(uintptr(0)))), nil
+}
+
+// parseNumaNodes decodes the SYSTEM_LOGICAL_PROCESSOR_INFORMATION_EX records
+// of NUMA nodes. Each record starts with its relationship and size, followed
+// by a NUMA_NODE_RELATIONSHIP: the NodeNumber DWORD at offset 8, the
+// GroupCount WORD at offset 30 and the GroupMasks at offset 32. Before Windows
+// Server 2022, GroupCount is 0 and a single mask is reported. A GROUP_AFFINITY
+// is made of a pointer sized KAFFINITY mask, the group WORD and 3 reserved WORDs.
+func parseNumaNodes(buf []byte, ptrSize int) []NumaNode {
+	const masksOffset = 32
+	affinitySize := ptrSize + 8
+
+	var nodes []NumaNode
+	for len(buf) >= masksOffset {
+		size := int(binary.LittleEndian.Uint32(buf[4:8]))
+		if size < masksOffset || size > len(buf) {
+			break
+		}
+		record := buf[:size]
+		buf = buf[size:]
+		if binary.LittleEndian.Uint32(record[0:4]) != relationNumaNode {
+			continue
+		}
+
+		groups := int(binary.LittleEndian.Uint16(record[30:32]))
+		if groups == 0 {
+			groups = 1
+		}
+		node := NumaNode{Number: binary.LittleEndian.Uint32(record[8:12])}
+		for i := 0; i < groups; i++ {
+			offset := masksOffset + i*affinitySize
+			if offset+ptrSize > len(record) {
+				break
+			}
+			mask := record[offset : offset+ptrSize]
+			if ptrSize == 8 {
+				node.ProcessorCount += bits.OnesCount64(binary.LittleEndian.Uint64(mask))
+			} else {
+				node.ProcessorCount += bits.OnesCount32(binary.LittleEndian.Uint32(mask))
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// GetNumaAvailableMemoryNode returns the amount of memory available in the
+// NUMA node, in bytes.
+// https://docs.microsoft.com/en-us/windows/win32/api/systemtopologyapi/nf-systemtopologyapi-getnumaavailablememorynodeex
+func GetNumaAvailableMemoryNode(node uint16) (uint64, error) {
+	var available uint64
+	r1, _, err := procGetNumaAvailableMemoryNodeEx.Call(uintptr(node), uintptr(unsafe.Pointer(&available)))
+	if r1 == 0 {
+		return 0, err
+	}
+	return available, nil
+}
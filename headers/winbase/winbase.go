@@ -0,0 +1,46 @@
+package winbase
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Values of SystemPowerStatus fields
+const (
+	ACLineStatusOffline       = 0
+	ACLineStatusOnline        = 1
+	ACLineStatusUnknown       = 255
+	BatteryFlagNoBattery      = 128
+	BatteryFlagUnknown        = 255
+	BatteryLifePercentUnknown = 255
+	BatteryLifeTimeUnknown    = 0xFFFFFFFF
+)
+
+// SystemPowerStatus is a wrapper for SYSTEM_POWER_STATUS
+// https://docs.microsoft.com/en-us/windows/win32/api/winbase/ns-winbase-system_power_status
+type SystemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+var (
+	kernel32                 = windows.NewLazySystemDLL("kernel32.dll")
+	procGetSystemPowerStatus = kernel32.NewProc("GetSystemPowerStatus")
+)
+
+// GetSystemPowerStatus retrieves the power status of the system: whether it runs on AC or battery power, and the
+// charge of the battery. The values of multiple batteries are aggregated, as shown by the battery indicator.
+// https://docs.microsoft.com/en-us/windows/win32/api/winbase/nf-winbase-getsystempowerstatus
+func GetSystemPowerStatus() (SystemPowerStatus, error) {
+	var status SystemPowerStatus
+	r1, _, err := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status)))
+	if r1 == 0 {
+		return SystemPowerStatus{}, err
+	}
+	return status, nil
+}
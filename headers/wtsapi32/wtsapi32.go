@@ -0,0 +1,112 @@
+package wtsapi32
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Session is an idiomatic wrapper for WTS_SESSION_INFO
+type Session struct {
+	ID          uint32
+	StationName string
+	State       uint32
+}
+
+// wtsInfo is a wrapper for WTSINFOW
+// https://docs.microsoft.com/en-us/windows/win32/api/wtsapi32/ns-wtsapi32-wtsinfow
+type wtsInfo struct {
+	State                   uint32
+	SessionID               uint32
+	IncomingBytes           uint32
+	OutgoingBytes           uint32
+	IncomingFrames          uint32
+	OutgoingFrames          uint32
+	IncomingCompressedBytes uint32
+	OutgoingCompressedBytes uint32
+	WinStationName          [32]uint16
+	Domain                  [17]uint16
+	UserName                [21]uint16
+	// Aligns the LARGE_INTEGER fields on 8 bytes, as on 386 Go only aligns
+	// them on 4 bytes.
+	_              uint32
+	ConnectTime    int64
+	DisconnectTime int64
+	LastInputTime  int64
+	LogonTime      int64
+	CurrentTime    int64
+}
+
+// SessionInfo is an idiomatic wrapper for WTSINFOW. Times are FILETIMEs, 0
+// when unknown.
+type SessionInfo struct {
+	Domain         string
+	UserName       string
+	ConnectTime    int64
+	DisconnectTime int64
+	LastInputTime  int64
+	LogonTime      int64
+	CurrentTime    int64
+}
+
+// wtsSessionInfo is the WTS_INFO_CLASS of WTSINFOW
+const wtsSessionInfo = 24
+
+var (
+	wtsapi32                        = windows.NewLazySystemDLL("wtsapi32.dll")
+	procWTSQuerySessionInformationW = wtsapi32.NewProc("WTSQuerySessionInformationW")
+)
+
+// EnumerateSessions lists the sessions of the local server, including the
+// services session and the listeners.
+// https://docs.microsoft.com/en-us/windows/win32/api/wtsapi32/nf-wtsapi32-wtsenumeratesessionsw
+func EnumerateSessions() ([]Session, error) {
+	var (
+		infos *windows.WTS_SESSION_INFO
+		count uint32
+	)
+	if err := windows.WTSEnumerateSessions(0, 0, 1, &infos, &count); err != nil {
+		return nil, err
+	}
+	defer windows.WTSFreeMemory(uintptr(unsafe.Pointer(infos)))
+
+	sessions := make([]Session, 0, count)
+	for _, info := range (*[1 << 20]windows.WTS_SESSION_INFO)(unsafe.Pointer(infos))[:count:count] {
+		sessions = append(sessions, Session{
+			ID:          info.SessionID,
+			StationName: windows.UTF16PtrToString(info.WindowStationName),
+			State:       info.State,
+		})
+	}
+	return sessions, nil
+}
+
+// QuerySessionInfo retrieves the user and times of a session of the local server.
+// https://docs.microsoft.com/en-us/windows/win32/api/wtsapi32/nf-wtsapi32-wtsquerysessioninformationw
+func QuerySessionInfo(sessionID uint32) (SessionInfo, error) {
+	var (
+		buf   *wtsInfo
+		bytes uint32
+	)
+	r1, _, err := procWTSQuerySessionInformationW.Call(
+		0, // WTS_CURRENT_SERVER_HANDLE
+		uintptr(sessionID),
+		wtsSessionInfo,
+		uintptr(unsafe.Pointer(&buf)),
+		uintptr(unsafe.Pointer(&bytes)),
+	)
+	if r1 == 0 {
+		return SessionInfo{}, err
+	}
+	defer windows.WTSFreeMemory(uintptr(unsafe.Pointer(buf)))
+
+	return SessionInfo{
+		Domain:         windows.UTF16ToString(buf.Domain[:]),
+		UserName:       windows.UTF16ToString(buf.UserName[:]),
+		ConnectTime:    buf.ConnectTime,
+		DisconnectTime: buf.DisconnectTime,
+		LastInputTime:  buf.LastInputTime,
+		LogonTime:      buf.LogonTime,
+		CurrentTime:    buf.CurrentTime,
+	}, nil
+}
@@ -63,7 +63,7 @@ func AddFlags(a *kingpin.Application) {
 		Default(origLogger.Level.String()).
 		StringVar(&s.level)
 	defaultFormat := url.URL{Scheme: "logger", Opaque: "stderr"}
-	a.Flag("log.format", `Set the log target and format. Example: "logger:syslog?appname=bob&local=7" or "logger:stdout?json=true"`).
+	a.Flag("log.format", `Set the log target and format. Example: "logger:syslog?appname=bob&local=7" or "logger:stdout?json=true". "json" and "logfmt" are shorthands for logging to stderr in these formats`).
 		Default(defaultFormat.String()).
 		StringVar(&s.format)
 	a.Action(s.apply)
@@ -190,7 +190,17 @@ func (l logger) SetLevel(level string) error {
 	return nil
 }
 
+// formatShorthands maps the formats which can be set without a target to the
+// equivalent logger URL, logging to stderr.
+var formatShorthands = map[string]string{
+	"logfmt": "logger:stderr",
+	"json":   "logger:stderr?json=true",
+}
+
 func (l logger) SetFormat(format string) error {
+	if f, ok := formatShorthands[format]; ok {
+		format = f
+	}
 	u, err := url.Parse(format)
 	if err != nil {
 		return err